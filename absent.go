@@ -0,0 +1,72 @@
+package traefik_plugin_validate_headers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// compileAbsent validates the configuration of a header that must be absent.
+func compileAbsent(vHeader *SingleHeader) error {
+	names := vHeader.absentNames()
+	if len(names) == 0 && vHeader.NamePattern == "" {
+		return errors.New("missing header name")
+	}
+
+	if vHeader.NamePattern != "" {
+		names = []string{vHeader.NamePattern}
+	}
+
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("empty name found")
+		}
+	}
+
+	if len(vHeader.Values) > 0 || len(vHeader.matchModes()) > 0 || vHeader.hasConstraints() || len(vHeader.Transforms) > 0 || vHeader.Source != "" {
+		return errors.New("absent can't be combined with values, match modes, constraints, transforms or a source")
+	}
+
+	return nil
+}
+
+// checkAbsent checks that none of the names of the header, or no header matching its name pattern, are
+// present in the request, including equivalent spellings allowed by the name resolution policy. With strip
+// enabled present headers are removed from the request instead.
+func checkAbsent(vHeader *SingleHeader, req *http.Request) *Failure {
+	var names []string
+
+	if vHeader.namePattern != nil {
+		names = matchingNames(vHeader.namePattern, req, vHeader.nameResolution)
+	} else {
+		for _, name := range vHeader.absentNames() {
+			names = append(names, equivalentNames(req, name, vHeader.nameResolution)...)
+		}
+	}
+
+	for _, name := range names {
+		if vHeader.IsStrip() {
+			if vHeader.IsDebug() {
+				fmt.Println("validate-headers (debug): Stripping header:", name)
+			}
+
+			req.Header.Del(name)
+
+			continue
+		}
+
+		return &Failure{Header: name, Reason: ReasonForbidden}
+	}
+
+	return nil
+}
+
+// absentNames returns the name and the names of a header that must be absent.
+func (s *SingleHeader) absentNames() []string {
+	if s.Name == "" {
+		return s.Names
+	}
+
+	return append([]string{s.Name}, s.Names...)
+}
@@ -0,0 +1,245 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAbsent(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//AbsentConfig
+		{
+			config: &Config{
+				MatchType: string(MatchOne),
+				Headers: []SingleHeader{
+					{
+						Name:   "X-Internal-User",
+						Absent: Bool(true),
+					},
+					{
+						Names: []string{
+							"X-Original-URL",
+							"X-Rewrite-URL",
+						},
+						Absent: Bool(true),
+					},
+					{
+						Name:      "X-Api-Key",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"secret",
+						},
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "Absent_Success",
+					headers: map[string]string{
+						"X-Api-Key": "secret",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Absent_Fail_Present",
+					headers: map[string]string{
+						"X-Api-Key":       "secret",
+						"X-Internal-User": "admin",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Internal-User: forbidden",
+				},
+				{
+					name: "Absent_Fail_EmptyValue",
+					headers: map[string]string{
+						"X-Api-Key":       "secret",
+						"X-Internal-User": "",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Internal-User: forbidden",
+				},
+				{
+					name: "Absent_Fail_Names",
+					headers: map[string]string{
+						"X-Api-Key":     "secret",
+						"X-Rewrite-Url": "/admin",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Rewrite-URL: forbidden",
+				},
+				{
+					name: "Absent_Fail_OtherHeaderStillChecked",
+					headers: map[string]string{
+						"X-Api-Key": "guess",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Api-Key: mismatch",
+				},
+			},
+		},
+		//AbsentOnlyConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:   "X-Internal-User",
+						Absent: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:           "AbsentOnly_Success",
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "AbsentOnly_Fail_Present",
+					headers: map[string]string{
+						"X-Internal-User": "admin",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// AbsentWithValues
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Internal-User",
+						MatchType: string(MatchNone),
+						Values:    []string{"admin"},
+						Absent:    Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "AbsentWithValues",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0]: absent can't be combined with values, match modes, constraints, transforms or a source"),
+				},
+			},
+		},
+		// AbsentUnknownMatchType
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Internal-User",
+						MatchType: "nnone",
+						Absent:    Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "AbsentUnknownMatchType",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].matchtype: unknown matchtype \"nnone\", allowed: all, one, none, atleast, atmost, exactly"),
+				},
+			},
+		},
+		// NamesWithoutAbsent
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Internal-User",
+						MatchType: string(MatchOne),
+						Values:    []string{"admin"},
+						Names:     []string{"X-Original-URL"},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "NamesWithoutAbsent",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0]: names and strip can only be used in combination with absent"),
+				},
+			},
+		},
+		// AbsentWithoutName
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Absent: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "AbsentWithoutName",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0]: missing header name"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
+
+func TestAbsentStrip(t *testing.T) {
+	config := &Config{
+		Headers: []SingleHeader{
+			{
+				Names: []string{
+					"X-Internal-User",
+					"X-Original-URL",
+				},
+				Absent: Bool(true),
+				Strip:  Bool(true),
+			},
+			{
+				Name:      "X-Api-Key",
+				MatchType: string(MatchOne),
+				Values: []string{
+					"secret",
+				},
+			},
+		},
+	}
+
+	var forwarded http.Header
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req.Header.Clone()
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	h, err := New(nil, next, config, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Add("X-Internal-User", "admin")
+	req.Header.Add("X-Internal-User", "root")
+	req.Header.Set("X-Original-URL", "/admin")
+	req.Header.Set("X-Api-Key", "secret")
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	for _, name := range []string{"X-Internal-User", "X-Original-URL"} {
+		if values := forwarded.Values(name); len(values) > 0 {
+			t.Errorf("header %s was forwarded with %q", name, values)
+		}
+	}
+
+	if forwarded.Get("X-Api-Key") != "secret" {
+		t.Errorf("header X-Api-Key was not forwarded")
+	}
+}
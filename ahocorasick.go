@@ -0,0 +1,219 @@
+package traefik_plugin_validate_headers
+
+import (
+	"sort"
+)
+
+// ahoCorasick is an Aho-Corasick automaton that finds which configured values a header value contains in a
+// single pass over the header value, whatever the number of configured values.
+type ahoCorasick struct {
+	nodes []acNode
+	edges []acEdge
+	// weights is the number of configured values of every unique pattern, so duplicates count as in a scan.
+	weights []int
+	total   int
+}
+
+// acNode is a node of the trie, with the outgoing edges at edges[edges:edges+nEdges], sorted by label.
+type acNode struct {
+	edges  int32
+	nEdges int32
+	fail   int32
+	// dict is the nearest node on the fail chain that ends a pattern; the root when there is none.
+	dict int32
+	// pattern is the unique pattern that ends at this node; -1 when none does.
+	pattern int32
+}
+
+// acEdge is an edge of the trie.
+type acEdge struct {
+	label byte
+	next  int32
+}
+
+// compileAhoCorasick compiles the configured values, which are never empty, into an automaton.
+func compileAhoCorasick(values []string) *ahoCorasick {
+	weights := make(map[string]int, len(values))
+	for _, value := range values {
+		weights[value]++
+	}
+
+	// Inserting the sorted patterns creates the children of every node in the order of their labels.
+	patterns := make([]string, 0, len(weights))
+	for pattern := range weights {
+		patterns = append(patterns, pattern)
+	}
+
+	sort.Strings(patterns)
+
+	a := &ahoCorasick{nodes: []acNode{{pattern: -1}}, weights: make([]int, len(patterns)), total: len(values)}
+
+	// parents and labels hold the parent and the label of the edge to every node but the root.
+	var parents []int32
+
+	var labels []byte
+
+	// path holds the nodes of the previous pattern; a pattern only shares the nodes of its common prefix with
+	// the previous pattern, as the patterns are sorted.
+	path := []int32{0}
+	previous := ""
+
+	for i, pattern := range patterns {
+		common := 0
+		for common < len(pattern) && common < len(previous) && pattern[common] == previous[common] {
+			common++
+		}
+
+		path = path[:common+1]
+
+		for j := common; j < len(pattern); j++ {
+			child := int32(len(a.nodes))
+			a.nodes = append(a.nodes, acNode{pattern: -1})
+			parents = append(parents, path[j])
+			labels = append(labels, pattern[j])
+			path = append(path, child)
+		}
+
+		a.nodes[path[len(pattern)]].pattern = int32(i)
+		a.weights[i] = weights[pattern]
+		previous = pattern
+	}
+
+	a.compileEdges(parents, labels)
+	a.compileFailLinks()
+
+	return a
+}
+
+// compileEdges lays out the edges of every node contiguously, in the order in which the nodes were created.
+func (a *ahoCorasick) compileEdges(parents []int32, labels []byte) {
+	for _, parent := range parents {
+		a.nodes[parent].nEdges++
+	}
+
+	offset := int32(0)
+	for i := range a.nodes {
+		a.nodes[i].edges = offset
+		offset += a.nodes[i].nEdges
+		a.nodes[i].nEdges = 0
+	}
+
+	a.edges = make([]acEdge, len(parents))
+
+	for i, parent := range parents {
+		node := &a.nodes[parent]
+		a.edges[node.edges+node.nEdges] = acEdge{label: labels[i], next: int32(i + 1)}
+		node.nEdges++
+	}
+}
+
+// compileFailLinks sets the fail and dictionary links of the nodes, breadth-first.
+func (a *ahoCorasick) compileFailLinks() {
+	queue := make([]int32, 1, len(a.nodes))
+
+	for head := 0; head < len(queue); head++ {
+		node := queue[head]
+
+		for _, edge := range a.edges[a.nodes[node].edges : a.nodes[node].edges+a.nodes[node].nEdges] {
+			child := &a.nodes[edge.next]
+			queue = append(queue, edge.next)
+
+			if node == 0 {
+				continue
+			}
+
+			child.fail = a.step(a.nodes[node].fail, edge.label)
+
+			if a.nodes[child.fail].pattern >= 0 {
+				child.dict = child.fail
+			} else {
+				child.dict = a.nodes[child.fail].dict
+			}
+		}
+	}
+}
+
+// next returns the child of the node for the label; -1 when there is none.
+func (a *ahoCorasick) next(node int32, label byte) int32 {
+	lo, hi := a.nodes[node].edges, a.nodes[node].edges+a.nodes[node].nEdges
+
+	for lo < hi {
+		mid := int32(uint32(lo+hi) >> 1)
+		if a.edges[mid].label < label {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	if lo < a.nodes[node].edges+a.nodes[node].nEdges && a.edges[lo].label == label {
+		return a.edges[lo].next
+	}
+
+	return -1
+}
+
+// step returns the state after reading the label, following the fail links until a node has a child for it.
+func (a *ahoCorasick) step(state int32, label byte) int32 {
+	for {
+		if next := a.next(state, label); next >= 0 {
+			return next
+		}
+
+		if state == 0 {
+			return 0
+		}
+
+		state = a.nodes[state].fail
+	}
+}
+
+// matchCount returns the number of configured values that the value contains, as counting strings.Contains
+// for every configured value would. With first it returns as soon as a configured value is found, which is
+// enough for the match types that only check whether any value matched.
+func (a *ahoCorasick) matchCount(value string, first bool) int {
+	// The patterns that were found; up to 256 patterns are tracked without allocating.
+	var small [4]uint64
+
+	var seen []uint64
+
+	count := 0
+	state := int32(0)
+
+	for i := 0; i < len(value); i++ {
+		state = a.step(state, value[i])
+
+		out := state
+		if a.nodes[out].pattern < 0 {
+			out = a.nodes[out].dict
+		}
+
+		for ; out != 0; out = a.nodes[out].dict {
+			pattern := a.nodes[out].pattern
+			if first {
+				return a.weights[pattern]
+			}
+
+			if seen == nil {
+				if words := (len(a.weights) + 63) / 64; words <= len(small) {
+					seen = small[:words]
+				} else {
+					seen = make([]uint64, words)
+				}
+			}
+
+			if seen[pattern/64]&(1<<(pattern%64)) != 0 {
+				continue
+			}
+
+			seen[pattern/64] |= 1 << (pattern % 64)
+			count += a.weights[pattern]
+
+			if count == a.total {
+				return count
+			}
+		}
+	}
+
+	return count
+}
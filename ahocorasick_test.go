@@ -0,0 +1,220 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// containsCount counts the configured values that the value contains, as checkContains does without an automaton.
+func containsCount(values []string, value string) int {
+	count := 0
+
+	for _, configured := range values {
+		if strings.Contains(value, configured) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestAhoCorasick(t *testing.T) {
+	tests := []struct {
+		values []string
+		value  string
+	}{
+		{values: []string{"he", "she", "his", "hers"}, value: "ushers"},
+		{values: []string{"he", "she", "his", "hers"}, value: "ahishers"},
+		{values: []string{"he", "she", "his", "hers"}, value: "xyz"},
+		{values: []string{"a", "aa", "aaa"}, value: "aaaa"},
+		{values: []string{"a", "a", "b"}, value: "ab"},
+		{values: []string{"a", "a", "b"}, value: "a"},
+		{values: []string{"abcd", "bc", "c"}, value: "abcx"},
+		{values: []string{"abcd", "bcde", "cd"}, value: "abcde"},
+		{values: []string{"CN=example.com", "O=Example"}, value: "Subject=\"C=NL,O=Example,CN=example.com\""},
+		{values: []string{"é", "ü"}, value: "über"},
+		{values: []string{"needle"}, value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v_%s", tt.values, tt.value), func(t *testing.T) {
+			automaton := compileAhoCorasick(tt.values)
+			want := containsCount(tt.values, tt.value)
+
+			if got := automaton.matchCount(tt.value, false); got != want {
+				t.Errorf("got %d matches, want %d", got, want)
+			}
+
+			if got := automaton.matchCount(tt.value, true); (got > 0) != (want > 0) {
+				t.Errorf("got %d for the first match, want a match: %v", got, want > 0)
+			}
+		})
+	}
+}
+
+func TestAhoCorasickManyPatterns(t *testing.T) {
+	values := make([]string, 1000)
+	for i := range values {
+		values[i] = fmt.Sprintf("id-%d;", i)
+	}
+
+	automaton := compileAhoCorasick(values)
+
+	for _, value := range []string{"id-1;", "xid-999;id-10;id-100;", "id-1000;", "id-7;id-7;", ""} {
+		if got, want := automaton.matchCount(value, false), containsCount(values, value); got != want {
+			t.Errorf("%q: got %d matches, want %d", value, got, want)
+		}
+	}
+}
+
+// FuzzAhoCorasick checks the automaton against strings.Contains for every configured value.
+func FuzzAhoCorasick(f *testing.F) {
+	f.Add("he,she,his,hers", "ushers")
+	f.Add("a,aa,aaa,a", "aaaa")
+	f.Add("abcd,bc,c", "abcx")
+
+	f.Fuzz(func(t *testing.T, values, value string) {
+		var patterns []string
+
+		for _, pattern := range strings.Split(values, ",") {
+			if pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+
+		if len(patterns) == 0 {
+			return
+		}
+
+		automaton := compileAhoCorasick(patterns)
+		want := containsCount(patterns, value)
+
+		if got := automaton.matchCount(value, false); got != want {
+			t.Errorf("values %q in %q: got %d matches, want %d", patterns, value, got, want)
+		}
+
+		if got := automaton.matchCount(value, true); (got > 0) != (want > 0) {
+			t.Errorf("values %q in %q: got %d for the first match, want a match: %v", patterns, value, got, want > 0)
+		}
+	})
+}
+
+func TestLargeValueLists(t *testing.T) {
+	values := make([]string, 10000)
+	for i := range values {
+		values[i] = fmt.Sprintf("tenant-%04d", i)
+	}
+
+	patterns := make([]string, 10000)
+	for i := range patterns {
+		patterns[i] = fmt.Sprintf("bad-%04d;", i)
+	}
+
+	configTestPairs := []TestConfig{
+		//LargeAllowlistConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{Name: "X-Tenant", MatchType: string(MatchOne), Values: values, Required: Bool(true)},
+				},
+				Error: ErrorConfig{ReasonHeader: "X-Validation-Reason"},
+			},
+			tests: []Test{
+				{name: "LargeAllowlist_Success", headers: map[string]string{"X-Tenant": "tenant-9999"}, expectedStatus: http.StatusOK},
+				{name: "LargeAllowlist_Fail", headers: map[string]string{"X-Tenant": "tenant-10000"}, expectedStatus: http.StatusForbidden, expectedReason: "X-Tenant: mismatch"},
+			},
+		},
+		//LargeBlocklistConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{Name: "X-Tenant", MatchType: string(MatchNone), Values: values},
+				},
+				Error: ErrorConfig{ReasonHeader: "X-Validation-Reason"},
+			},
+			tests: []Test{
+				{name: "LargeBlocklist_Success", headers: map[string]string{"X-Tenant": "tenant-x"}, expectedStatus: http.StatusOK},
+				{name: "LargeBlocklist_Fail", headers: map[string]string{"X-Tenant": "tenant-0042"}, expectedStatus: http.StatusForbidden, expectedReason: "X-Tenant: mismatch"},
+			},
+		},
+		//LargeContainsNoneConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{Name: "X-Tags", MatchType: string(MatchNone), Values: patterns, Contains: Bool(true)},
+				},
+				Error: ErrorConfig{ReasonHeader: "X-Validation-Reason"},
+			},
+			tests: []Test{
+				{name: "LargeContainsNone_Success", headers: map[string]string{"X-Tags": "good-0001;bad-1;"}, expectedStatus: http.StatusOK},
+				{name: "LargeContainsNone_Fail", headers: map[string]string{"X-Tags": "good-0001;bad-9999;"}, expectedStatus: http.StatusForbidden, expectedReason: "X-Tags: mismatch"},
+			},
+		},
+		//LargeContainsAtLeastConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{Name: "X-Tags", MatchType: string(MatchAtLeast), Count: 2, Values: patterns, Contains: Bool(true)},
+				},
+				Error: ErrorConfig{ReasonHeader: "X-Validation-Reason"},
+			},
+			tests: []Test{
+				{name: "LargeContainsAtLeast_Success", headers: map[string]string{"X-Tags": "bad-0001;bad-0002;"}, expectedStatus: http.StatusOK},
+				{name: "LargeContainsAtLeast_Fail_Repeated", headers: map[string]string{"X-Tags": "bad-0001;bad-0001;"}, expectedStatus: http.StatusForbidden, expectedReason: "X-Tags: mismatch"},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
+
+func TestCompiledValueLists(t *testing.T) {
+	values := []string{"a", "b", "c", "d", "e", "f", "g", "a"}
+
+	configTestPairs := []TestConfig{
+		//CompiledExactNoneConfig
+		{
+			config: &Config{
+				MatchType: string(MatchNone),
+				Headers: []SingleHeader{
+					{Name: "X-Value", MatchType: string(MatchOne), Values: values},
+				},
+				Error: ErrorConfig{ReasonHeader: "X-Validation-Reason"},
+			},
+			tests: []Test{
+				{name: "CompiledExactNone_Success", headers: map[string]string{"X-Value": "a"}, expectedStatus: http.StatusOK},
+				{name: "CompiledExactNone_Fail", headers: map[string]string{"X-Value": "x"}, expectedStatus: http.StatusForbidden, expectedReason: "X-Value: mismatch"},
+			},
+		},
+		//CompiledContainsExactlyConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{Name: "X-Value", MatchType: string(MatchExactly), Count: 3, Values: values, Contains: Bool(true)},
+				},
+				Error: ErrorConfig{ReasonHeader: "X-Validation-Reason"},
+			},
+			tests: []Test{
+				{name: "CompiledContainsExactly_Success_Duplicate", headers: map[string]string{"X-Value": "ab"}, expectedStatus: http.StatusOK},
+				{name: "CompiledContainsExactly_Fail", headers: map[string]string{"X-Value": "abc"}, expectedStatus: http.StatusForbidden, expectedReason: "X-Value: mismatch"},
+			},
+		},
+		//CompiledContainsAllConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{Name: "X-Value", MatchType: string(MatchAll), Values: values, Contains: Bool(true)},
+				},
+				Error: ErrorConfig{ReasonHeader: "X-Validation-Reason"},
+			},
+			tests: []Test{
+				{name: "CompiledContainsAll_Success", headers: map[string]string{"X-Value": "gfedcba"}, expectedStatus: http.StatusOK},
+				{name: "CompiledContainsAll_Fail", headers: map[string]string{"X-Value": "abcdef"}, expectedStatus: http.StatusForbidden, expectedReason: "X-Value: mismatch"},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
@@ -0,0 +1,206 @@
+// Command validate-headers tests a validate-headers configuration against request fixtures without Traefik.
+//
+// Usage:
+//
+//	validate-headers -config dynamic.yaml [-middleware name] [-requests requests.yaml]
+//	validate-headers -config plugin.yaml [-X method] [-H 'Name: value']... [-expect status] [-reason reason] path-or-url...
+//
+// The configuration is a dynamic configuration with a middleware that uses the plugin, or a bare plugin
+// configuration. Requests are read from the `requests` list of a YAML file, or built curl-like from the
+// flags for every path or URL argument. The exit status is 1 when the configuration is invalid or a request
+// doesn't get the expected response, and 2 for usage errors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	validateheaders "github.com/frankforpresident/traefik-plugin-validate-headers"
+	"github.com/frankforpresident/traefik-plugin-validate-headers/internal/policytest"
+)
+
+// headerFlags collects the repeatable -H flag.
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("expected 'Name: value', got %q", value)
+	}
+
+	*h = append(*h, value)
+
+	return nil
+}
+
+// parseArgs parses the flags and returns the paths and URLs. Unlike flags.Parse it doesn't stop at the first
+// path, so flags such as -H can follow the URL as with curl; arguments after `--` are always paths.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var paths []string
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return paths, nil
+		}
+
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(paths, rest...), nil
+		}
+
+		paths = append(paths, rest[0])
+		args = rest[1:]
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate-headers", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	configFile := flags.String("config", "", "dynamic configuration or bare plugin configuration (YAML)")
+	plugin := flags.String("plugin", policytest.DefaultPlugin, "name of the plugin in the dynamic configuration")
+	middleware := flags.String("middleware", "", "middleware to test when more than one uses the plugin")
+	requestsFile := flags.String("requests", "", "YAML file with a `requests` list of request fixtures")
+	method := flags.String("X", "", "method of the requests given as arguments (default GET)")
+	host := flags.String("host", "", "host of the requests given as arguments")
+	status := flags.Int("expect", 0, "expected status of the requests given as arguments (default 200)")
+	reason := flags.String("reason", "", "expected reason of the requests given as arguments, e.g. 'X-Api-Key: missing'")
+
+	var headers headerFlags
+	flags.Var(&headers, "H", "header of the requests given as arguments, 'Name: value' (repeatable)")
+
+	paths, err := parseArgs(flags, args)
+	if err != nil {
+		return 2
+	}
+
+	if *configFile == "" || (*requestsFile == "" && len(paths) == 0) {
+		fmt.Fprintln(stderr, "validate-headers: -config and -requests or at least one path or URL are required")
+		flags.Usage()
+
+		return 2
+	}
+
+	data, err := os.ReadFile(*configFile)
+	if err != nil {
+		fmt.Fprintln(stderr, "validate-headers:", err)
+		return 1
+	}
+
+	config, err := policytest.LoadConfig(data, *plugin, *middleware)
+	if err != nil {
+		fmt.Fprintf(stderr, "validate-headers: %s: %v\n", *configFile, err)
+		return 1
+	}
+
+	diagnostics := validateheaders.ValidateConfig(config)
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(stderr, diagnostic)
+	}
+
+	if len(diagnostics.Errors()) > 0 {
+		return 1
+	}
+
+	var cases []policytest.Case
+
+	if *requestsFile != "" {
+		data, err := os.ReadFile(*requestsFile)
+		if err != nil {
+			fmt.Fprintln(stderr, "validate-headers:", err)
+			return 1
+		}
+
+		cases, err = policytest.LoadCases(data)
+		if err != nil {
+			fmt.Fprintf(stderr, "validate-headers: %s: %v\n", *requestsFile, err)
+			return 1
+		}
+	}
+
+	for _, path := range paths {
+		c := policytest.Case{
+			Name:    path,
+			Method:  *method,
+			Host:    *host,
+			Path:    path,
+			Headers: make(map[string]policytest.Values),
+			Status:  *status,
+			Reason:  *reason,
+		}
+
+		for _, header := range headers {
+			name, value, _ := strings.Cut(header, ":")
+			c.Headers[strings.TrimSpace(name)] = append(c.Headers[strings.TrimSpace(name)], strings.TrimSpace(value))
+		}
+
+		cases = append(cases, c)
+	}
+
+	results, err := policytest.Run(config, cases)
+	if err != nil {
+		fmt.Fprintln(stderr, "validate-headers:", err)
+		return 1
+	}
+
+	if !printResults(stdout, results) {
+		return 1
+	}
+
+	return 0
+}
+
+// printResults prints a table with a row per request and returns whether all requests passed.
+func printResults(w io.Writer, results []policytest.Result) bool {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESULT\tNAME\tMETHOD\tPATH\tSTATUS\tEXPECTED\tREASON")
+
+	failed := 0
+
+	for i := range results {
+		result := &results[i]
+
+		outcome := "PASS"
+		if !result.Passed() {
+			outcome = "FAIL"
+			failed++
+		}
+
+		method, path := result.Case.Method, result.Case.Path
+		if method == "" {
+			method = "GET"
+		}
+
+		if path == "" {
+			path = "/"
+		}
+
+		expected := fmt.Sprint(result.Case.ExpectedStatus())
+		if result.Case.Reason != "" {
+			expected += " (" + result.Case.Reason + ")"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", outcome, result.Case.Name, method, path, result.Status, expected, result.Reason)
+	}
+
+	tw.Flush()
+
+	fmt.Fprintf(w, "%d passed, %d failed\n", len(results)-failed, failed)
+
+	return failed == 0
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestRun(t *testing.T) {
+	requests := writeFile(t, "requests.yaml", `
+requests:
+  - name: allowed
+    headers:
+      MATCH_ONE_REQUIRED: A
+  - name: missing
+    status: 404
+    reason: "MATCH_ONE_REQUIRED: missing"
+`)
+
+	invalid := writeFile(t, "invalid.yaml", `
+headers:
+  - name: X-Api-Key
+    matchtype: nnone
+    values: [secret, ""]
+`)
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedStdout []string
+		expectedStderr []string
+	}{
+		{
+			name:         "RequestsFile",
+			args:         []string{"-config", "../../examples/config/dynamic/a-or-b.yaml", "-requests", requests},
+			expectedCode: 0,
+			expectedStdout: []string{
+				"RESULT  NAME     METHOD  PATH  STATUS  EXPECTED                           REASON",
+				"PASS    allowed  GET     /     200     200",
+				"PASS    missing  GET     /     404     404 (MATCH_ONE_REQUIRED: missing)  MATCH_ONE_REQUIRED: missing",
+				"2 passed, 0 failed",
+			},
+		},
+		{
+			name:         "CurlLikeArguments",
+			args:         []string{"-config", "../../examples/config/dynamic/regex.yaml", "-X", "POST", "-H", "MATCH_ONE_REGEX: 789", "/api", "http://regex.localhost/"},
+			expectedCode: 0,
+			expectedStdout: []string{
+				"PASS    /api                     POST    /api                     200     200",
+				"PASS    http://regex.localhost/  POST    http://regex.localhost/  200     200",
+			},
+		},
+		{
+			name:         "FlagsAfterURL",
+			args:         []string{"-config", "../../examples/config/dynamic/regex.yaml", "/api", "-H", "MATCH_ONE_REGEX: 123", "-expect", "403", "--", "-weird"},
+			expectedCode: 0,
+			expectedStdout: []string{
+				"PASS    /api    GET     /api    403     403",
+				"PASS    -weird  GET     -weird  403     403",
+				"2 passed, 0 failed",
+			},
+		},
+		{
+			name:         "UnexpectedStatus",
+			args:         []string{"-config", "../../examples/config/dynamic/regex.yaml", "-H", "MATCH_ONE_REGEX: 123", "/"},
+			expectedCode: 1,
+			expectedStdout: []string{
+				"FAIL    /     GET     /     403     200       MATCH_ONE_REGEX: mismatch",
+				"0 passed, 1 failed",
+			},
+		},
+		{
+			name:         "InvalidConfig",
+			args:         []string{"-config", invalid, "/"},
+			expectedCode: 1,
+			expectedStderr: []string{
+				`error: headers[0].values[1]: empty value found`,
+				`error: headers[0].matchtype: unknown matchtype "nnone", allowed: all, one, none, atleast, atmost, exactly`,
+			},
+		},
+		{
+			name:         "MissingRequests",
+			args:         []string{"-config", invalid},
+			expectedCode: 2,
+			expectedStderr: []string{
+				"validate-headers: -config and -requests or at least one path or URL are required",
+			},
+		},
+		{
+			name:         "InvalidHeaderFlag",
+			args:         []string{"-config", invalid, "-H", "MATCH_ONE_REGEX", "/"},
+			expectedCode: 2,
+			expectedStderr: []string{
+				`invalid value "MATCH_ONE_REGEX" for flag -H: expected 'Name: value', got "MATCH_ONE_REGEX"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(tt.args, &stdout, &stderr)
+			if code != tt.expectedCode {
+				t.Errorf("got exit code %d, want %d\nstdout:\n%s\nstderr:\n%s", code, tt.expectedCode, stdout.String(), stderr.String())
+			}
+
+			for _, line := range tt.expectedStdout {
+				if !strings.Contains(stdout.String(), line) {
+					t.Errorf("stdout doesn't contain %q:\n%s", line, stdout.String())
+				}
+			}
+
+			for _, line := range tt.expectedStderr {
+				if !strings.Contains(stderr.String(), line) {
+					t.Errorf("stderr doesn't contain %q:\n%s", line, stderr.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// CharsetASCIIPrintable allows printable ASCII characters (0x20-0x7E).
+	CharsetASCIIPrintable = "ascii-printable"
+	// CharsetToken allows the token characters of RFC 9110.
+	CharsetToken = "token"
+	// CharsetBase64 allows the standard and URL-safe base64 alphabets with trailing padding.
+	CharsetBase64 = "base64"
+	// CharsetHex allows hexadecimal digits.
+	CharsetHex = "hex"
+	// CharsetUUID allows hexadecimal digits and dashes.
+	CharsetUUID = "uuid"
+)
+
+// charsets maps the supported charset names to their validation functions.
+var charsets = map[string]func(string) bool{
+	CharsetASCIIPrintable: func(value string) bool {
+		return allRunes(value, func(r rune) bool { return r >= 0x20 && r <= 0x7e })
+	},
+	CharsetToken: func(value string) bool {
+		return allRunes(value, isTokenChar)
+	},
+	CharsetBase64: func(value string) bool {
+		trimmed := strings.TrimRight(value, "=")
+		if len(value)-len(trimmed) > 2 {
+			return false
+		}
+
+		return allRunes(trimmed, func(r rune) bool {
+			return isAlphaNum(r) || r == '+' || r == '/' || r == '-' || r == '_'
+		})
+	},
+	CharsetHex: func(value string) bool {
+		return allRunes(value, isHexDigit)
+	},
+	CharsetUUID: func(value string) bool {
+		return allRunes(value, func(r rune) bool { return isHexDigit(r) || r == '-' })
+	},
+}
+
+// checkLengthAndCharset checks the header value as it was sent against the length and charset constraints of
+// the header. They are checked before decoding, transforms and extraction, so an encoded value can't be used
+// to get around them.
+func checkLengthAndCharset(requestValue string, vHeader *SingleHeader) *Failure {
+	if vHeader.MinLength > 0 || vHeader.MaxLength > 0 {
+		length := utf8.RuneCountInString(requestValue)
+
+		if vHeader.MinLength > 0 && length < vHeader.MinLength {
+			return &Failure{Header: vHeader.Name, Reason: ReasonTooShort, Detail: fmt.Sprintf("length %d is below minimum of %d", length, vHeader.MinLength)}
+		}
+
+		if vHeader.MaxLength > 0 && length > vHeader.MaxLength {
+			return &Failure{Header: vHeader.Name, Reason: ReasonTooLong, Detail: fmt.Sprintf("length %d exceeds maximum of %d", length, vHeader.MaxLength)}
+		}
+	}
+
+	if vHeader.Charset != "" && !charsets[vHeader.Charset](requestValue) {
+		return &Failure{Header: vHeader.Name, Reason: ReasonInvalidCharset, Detail: fmt.Sprintf("value is not %s", vHeader.Charset)}
+	}
+
+	return nil
+}
+
+// checkFormat checks a matched value, after decoding, transforms and extraction, against the format of the header.
+func checkFormat(requestValue string, vHeader *SingleHeader) *Failure {
+	if vHeader.Format != "" && !formats[vHeader.Format](requestValue) {
+		return &Failure{Header: vHeader.Name, Reason: ReasonMalformed, Detail: fmt.Sprintf("value is not a valid %s", vHeader.Format)}
+	}
+
+	return nil
+}
+
+// checkLimits checks the request-wide limits on the number and total size of the request headers.
+func checkLimits(config *Config, req *http.Request) *Failure {
+	if config.MaxHeaderCount == 0 && config.MaxHeaderBytes == 0 {
+		return nil
+	}
+
+	count := 0
+	size := 0
+
+	for name, values := range req.Header {
+		for _, value := range values {
+			count++
+			size += len(name) + len(value)
+		}
+	}
+
+	if config.MaxHeaderCount > 0 && count > config.MaxHeaderCount {
+		return &Failure{Reason: ReasonTooManyHeaders, Detail: fmt.Sprintf("%d headers exceed maximum of %d", count, config.MaxHeaderCount)}
+	}
+
+	if config.MaxHeaderBytes > 0 && size > config.MaxHeaderBytes {
+		return &Failure{Reason: ReasonHeadersTooLarge, Detail: fmt.Sprintf("%d bytes exceed maximum of %d", size, config.MaxHeaderBytes)}
+	}
+
+	return nil
+}
+
+// validateConstraints returns the problems of the constraint configuration of a header.
+func validateConstraints(path string, vHeader *SingleHeader) Diagnostics {
+	var diagnostics Diagnostics
+
+	if vHeader.MinLength < 0 || vHeader.MaxLength < 0 {
+		diagnostics.errorf(path, "minlength and maxlength must not be negative")
+	} else if vHeader.MaxLength > 0 && vHeader.MinLength > vHeader.MaxLength {
+		diagnostics.errorf(path+".minlength", "minlength %d is greater than maxlength %d", vHeader.MinLength, vHeader.MaxLength)
+	}
+
+	if _, ok := charsets[vHeader.Charset]; vHeader.Charset != "" && !ok {
+		diagnostics.errorf(path+".charset", "unknown charset %q, allowed: %s, %s, %s, %s, %s", vHeader.Charset, CharsetASCIIPrintable, CharsetToken, CharsetBase64, CharsetHex, CharsetUUID)
+	}
+
+	if _, ok := formats[vHeader.Format]; vHeader.Format != "" && !ok {
+		diagnostics.errorf(path+".format", "unknown format %q, allowed: %s", vHeader.Format, strings.Join(formatNames(), ", "))
+	}
+
+	return diagnostics
+}
+
+// hasConstraints checks whether the header has constraints that apply independently of its values.
+func (s *SingleHeader) hasConstraints() bool {
+	return s.MinLength != 0 || s.MaxLength != 0 || s.Charset != "" || s.Format != ""
+}
+
+// allRunes checks whether every rune in value satisfies fn.
+func allRunes(value string, fn func(rune) bool) bool {
+	for _, r := range value {
+		if !fn(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isTokenChar checks whether r is a token character (tchar) as defined in RFC 9110.
+func isTokenChar(r rune) bool {
+	return isAlphaNum(r) || strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
+
+// isAlphaNum checks whether r is an ASCII letter or digit.
+func isAlphaNum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// isHexDigit checks whether r is a hexadecimal digit.
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
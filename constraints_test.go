@@ -0,0 +1,360 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCharsets(t *testing.T) {
+	tests := []struct {
+		charset string
+		value   string
+		valid   bool
+	}{
+		{charset: CharsetASCIIPrintable, value: "Mozilla/5.0 (X11; Linux x86_64)", valid: true},
+		{charset: CharsetASCIIPrintable, value: "Mozilla\x00", valid: false},
+		{charset: CharsetASCIIPrintable, value: "Mozilla\t5.0", valid: false},
+		{charset: CharsetASCIIPrintable, value: "Müller", valid: false},
+		{charset: CharsetToken, value: "gzip", valid: true},
+		{charset: CharsetToken, value: "x-custom_token.1~", valid: true},
+		{charset: CharsetToken, value: "two words", valid: false},
+		{charset: CharsetToken, value: "a,b", valid: false},
+		{charset: CharsetBase64, value: "aGVsbG8=", valid: true},
+		{charset: CharsetBase64, value: "aGVsbG8-_w", valid: true},
+		{charset: CharsetBase64, value: "aGVs=bG8", valid: false},
+		{charset: CharsetBase64, value: "aGVsbG8===", valid: false},
+		{charset: CharsetHex, value: "deadBEEF01", valid: true},
+		{charset: CharsetHex, value: "xyz", valid: false},
+		{charset: CharsetUUID, value: "123e4567-e89b-12d3-a456-426614174000", valid: true},
+		{charset: CharsetUUID, value: "123e4567 e89b", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.charset+"_"+tt.value, func(t *testing.T) {
+			if charsets[tt.charset](tt.value) != tt.valid {
+				t.Errorf("charset %s on %q: got %v, want %v", tt.charset, tt.value, !tt.valid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//LengthConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Request-Id",
+						MinLength: 8,
+						MaxLength: 128,
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "Length_Success",
+					headers: map[string]string{
+						"X-Request-Id": "req-12345",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Length_Fail_TooLong",
+					headers: map[string]string{
+						"X-Request-Id": strings.Repeat("a", 129),
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Request-Id: too-long",
+				},
+				{
+					name: "Length_Fail_TooShort",
+					headers: map[string]string{
+						"X-Request-Id": "abc",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Request-Id: too-short",
+				},
+				{
+					name: "Length_Fail_Missing",
+					headers: map[string]string{
+						"InvalidHeader": "invalidValue",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Request-Id: missing",
+				},
+			},
+		},
+		//CharsetOptionalConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:     "User-Agent",
+						Charset:  CharsetASCIIPrintable,
+						Required: Bool(false),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "Charset_Success",
+					headers: map[string]string{
+						"User-Agent": "curl/8.0",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Charset_Fail_ControlCharacter",
+					headers: map[string]string{
+						"User-Agent": "curl/8.0\x01",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "User-Agent: invalid-charset",
+				},
+				{
+					name: "Charset_Success_Missing",
+					headers: map[string]string{
+						"InvalidHeader": "invalidValue",
+					},
+					expectedStatus: http.StatusOK,
+				},
+			},
+		},
+		//RawValueConstraintsConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Request-Id",
+						MaxLength: 5,
+						Charset:   CharsetHex,
+						URLDecode: Bool(true),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "RawValueConstraints_Success",
+					headers: map[string]string{
+						"X-Request-Id": "AAAAA",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "RawValueConstraints_Fail_EncodedTooLong",
+					headers: map[string]string{
+						"X-Request-Id": "%41%41%41%41%41",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Request-Id: too-long",
+				},
+				{
+					name: "RawValueConstraints_Fail_EncodedCharset",
+					headers: map[string]string{
+						"X-Request-Id": "%41",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Request-Id: invalid-charset",
+				},
+			},
+		},
+		//ConstraintsWithValuesConfig
+		{
+			config: &Config{
+				MatchType: string(MatchOne),
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Api-Key",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"secret",
+						},
+						Required: Bool(false),
+					},
+					{
+						Name:      "X-Trace",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"abc",
+						},
+						Charset:  CharsetHex,
+						Required: Bool(false),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "ConstraintsWithValues_Success",
+					headers: map[string]string{
+						"X-Trace": "abc",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ConstraintsWithValues_Fail_Mismatch",
+					headers: map[string]string{
+						"X-Api-Key": "wrong",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Api-Key: mismatch",
+				},
+				{
+					name: "ConstraintsWithValues_Fail_ConstraintIsHard",
+					headers: map[string]string{
+						"X-Api-Key": "secret",
+						"X-Trace":   "xyz",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Trace: invalid-charset",
+				},
+			},
+		},
+		//RequestLimitsConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Request-Id",
+						MaxLength: 128,
+						Required:  Bool(false),
+					},
+				},
+				MaxHeaderCount: 3,
+				MaxHeaderBytes: 64,
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "RequestLimits_Success",
+					headers: map[string]string{
+						"A": "1",
+						"B": "2",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "RequestLimits_Fail_TooManyHeaders",
+					headers: map[string]string{
+						"A": "1",
+						"B": "2",
+						"C": "3",
+						"D": "4",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "too-many-headers",
+				},
+				{
+					name: "RequestLimits_Fail_TooLarge",
+					headers: map[string]string{
+						"A": strings.Repeat("a", 64),
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "headers-too-large",
+				},
+			},
+		},
+		// NegativeLength
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Request-Id",
+						MaxLength: -1,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "NegativeLength",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0]: minlength and maxlength must not be negative"),
+				},
+			},
+		},
+		// MinGreaterThanMax
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Request-Id",
+						MinLength: 10,
+						MaxLength: 5,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "MinGreaterThanMax",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].minlength: minlength 10 is greater than maxlength 5"),
+				},
+			},
+		},
+		// UnknownCharset
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:    "X-Request-Id",
+						Charset: "latin1",
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "UnknownCharset",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].charset: unknown charset \"latin1\", allowed: ascii-printable, token, base64, hex, uuid"),
+				},
+			},
+		},
+		// NegativeRequestLimits
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Request-Id",
+						MaxLength: 10,
+					},
+				},
+				MaxHeaderCount: -1,
+			},
+			tests: []Test{
+				{
+					name:          "NegativeRequestLimits",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: maxheadercount: must not be negative"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
+
+func TestFailureError(t *testing.T) {
+	failure := &Failure{Header: "X-Request-Id", Reason: ReasonTooLong, Detail: "length 129 exceeds maximum of 128"}
+
+	expected := "header X-Request-Id: too-long (length 129 exceeds maximum of 128)"
+	if failure.Error() != expected {
+		t.Errorf("got %q, want %q", failure.Error(), expected)
+	}
+
+	failure = &Failure{Reason: ReasonTooManyHeaders}
+	if failure.Error() != "too-many-headers" {
+		t.Errorf("got %q, want %q", failure.Error(), "too-many-headers")
+	}
+}
@@ -0,0 +1,85 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// isCountMatchType checks whether the match type compares the number of matches with a configured count.
+func isCountMatchType(matchType string) bool {
+	switch matchType {
+	case string(MatchAtLeast), string(MatchAtMost), string(MatchExactly):
+		return true
+	default:
+		return false
+	}
+}
+
+// validateCount checks whether the count can be satisfied by the number of possible matches.
+func validateCount(matchType string, count int, possible int) error {
+	if !isCountMatchType(matchType) {
+		if count != 0 {
+			return fmt.Errorf("count can only be used in combination with match type %s, %s or %s", MatchAtLeast, MatchAtMost, MatchExactly)
+		}
+
+		return nil
+	}
+
+	minimum := 0
+	if matchType == string(MatchAtLeast) {
+		minimum = 1
+	}
+
+	if count < minimum || count > possible {
+		return fmt.Errorf("count %d is impossible for match type %s, expected %d to %d", count, matchType, minimum, possible)
+	}
+
+	return nil
+}
+
+// compareCount applies a count match type to the number of matches.
+func compareCount(matchType string, matchCount int, count int) bool {
+	switch matchType {
+	case string(MatchAtLeast):
+		return matchCount >= count
+	case string(MatchAtMost):
+		return matchCount <= count
+	default:
+		return matchCount == count
+	}
+}
+
+// checkCount checks whether the number of configured headers that are present and match satisfies the count.
+// Malformed values and constraint violations reject the request whatever the count.
+func checkCount(headers []SingleHeader, matchType string, count int, req *http.Request) *Failure {
+	matchCount := 0
+
+	var buf [1]string
+
+	for i := range headers {
+		vHeader := &headers[i]
+		reqHeaderVals, failure := requestValues(req, vHeader, buf[:0])
+		if failure != nil {
+			return failure
+		}
+
+		if len(reqHeaderVals) == 0 {
+			continue
+		}
+
+		matched, failure := matchValues(req, reqHeaderVals, vHeader, checkMatches)
+		if failure != nil {
+			return failure
+		}
+
+		if matched {
+			matchCount++
+		}
+	}
+
+	if compareCount(matchType, matchCount, count) {
+		return nil
+	}
+
+	return &Failure{Reason: ReasonMismatch, Detail: fmt.Sprintf("%d of the configured headers matched, expected %s %d", matchCount, matchType, count)}
+}
@@ -0,0 +1,296 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCountMatchTypes(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//AtLeastHeadersConfig
+		{
+			config: &Config{
+				MatchType: string(MatchAtLeast),
+				Count:     2,
+				Headers: []SingleHeader{
+					{
+						Name:   "X-Request-Id",
+						Format: "uuid",
+					},
+					{
+						Name:      "X-Client",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"web",
+							"mobile",
+						},
+					},
+					{
+						Name:      "X-Region",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"eu-",
+						},
+						Prefix: Bool(true),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "AtLeastHeaders_Success",
+					headers: map[string]string{
+						"X-Client": "web",
+						"X-Region": "eu-west",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "AtLeastHeaders_Fail_OneMatches",
+					headers: map[string]string{
+						"X-Client": "web",
+						"X-Region": "us-east",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "mismatch",
+				},
+				{
+					name: "AtLeastHeaders_Fail_MalformedIsHard",
+					headers: map[string]string{
+						"X-Request-Id": "42",
+						"X-Client":     "web",
+						"X-Region":     "eu-west",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Request-Id: malformed",
+				},
+			},
+		},
+		//ExactlyOneHeaderConfig
+		{
+			config: &Config{
+				MatchType: string(MatchExactly),
+				Count:     1,
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Api-Key",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"key-",
+						},
+						Prefix: Bool(true),
+					},
+					{
+						Name:      "Authorization",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"Bearer ",
+						},
+						Prefix: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "ExactlyOneHeader_Success",
+					headers: map[string]string{
+						"Authorization": "Bearer abc",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ExactlyOneHeader_Fail_Both",
+					headers: map[string]string{
+						"X-Api-Key":     "key-123",
+						"Authorization": "Bearer abc",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name:           "ExactlyOneHeader_Fail_None",
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		//CountValuesConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Scopes",
+						MatchType: string(MatchAtLeast),
+						Count:     2,
+						Values: []string{
+							"read",
+							"write",
+							"admin",
+						},
+						Contains: Bool(true),
+					},
+					{
+						Name:      "X-Path",
+						MatchType: string(MatchAtMost),
+						Count:     1,
+						Values: []string{
+							`\.\.`,
+							`%2e`,
+							`//`,
+						},
+						Regex:    Bool(true),
+						Required: Bool(false),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "CountValues_Success",
+					headers: map[string]string{
+						"X-Scopes": "read write",
+						"X-Path":   "/a//b",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "CountValues_Fail_TooFewScopes",
+					headers: map[string]string{
+						"X-Scopes": "read",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Scopes: mismatch",
+				},
+				{
+					name: "CountValues_Fail_TooManyPatterns",
+					headers: map[string]string{
+						"X-Scopes": "read write admin",
+						"X-Path":   "/a//%2e%2e/b",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Path: mismatch",
+				},
+			},
+		},
+		//ExactlyValueConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Env",
+						MatchType: string(MatchExactly),
+						Count:     1,
+						Values: []string{
+							"prod",
+							"staging",
+						},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "ExactlyValue_Success",
+					headers: map[string]string{
+						"X-Env": "prod",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ExactlyValue_Fail",
+					headers: map[string]string{
+						"X-Env": "dev",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// ImpossibleHeaderCount
+		{
+			config: &Config{
+				MatchType: string(MatchAtLeast),
+				Count:     3,
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Api-Key",
+						MatchType: string(MatchOne),
+						Values:    []string{"a"},
+					},
+					{
+						Name:      "Authorization",
+						MatchType: string(MatchOne),
+						Values:    []string{"b"},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "ImpossibleHeaderCount",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: count: count 3 is impossible for match type atleast, expected 1 to 2"),
+				},
+			},
+		},
+		// ImpossibleExactValueCount
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Env",
+						MatchType: string(MatchAtLeast),
+						Count:     2,
+						Values:    []string{"prod", "staging"},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "ImpossibleExactValueCount",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].count: count 2 is impossible for match type atleast, expected 1 to 1"),
+				},
+			},
+		},
+		// NegativeCount
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Scopes",
+						MatchType: string(MatchAtMost),
+						Count:     -1,
+						Values:    []string{"read"},
+						Contains:  Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "NegativeCount",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].count: count -1 is impossible for match type atmost, expected 0 to 1"),
+				},
+			},
+		},
+		// CountWithoutCountMatchType
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Scopes",
+						MatchType: string(MatchOne),
+						Count:     1,
+						Values:    []string{"read"},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "CountWithoutCountMatchType",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].count: count can only be used in combination with match type atleast, atmost or exactly"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
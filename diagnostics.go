@@ -0,0 +1,277 @@
+package traefik_plugin_validate_headers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Severity tells whether a diagnostic prevents the plugin from starting.
+type Severity string
+
+const (
+	// SeverityError marks a configuration that is rejected by New.
+	SeverityError Severity = "error"
+	// SeverityWarning marks a configuration that is accepted, but likely doesn't do what was intended.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic describes a problem in the configuration at a path such as `headers[3].values[1]`.
+type Diagnostic struct {
+	Severity Severity
+	Path     string
+	Message  string
+}
+
+// String returns the diagnostic as `severity: path: message`.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Severity, d.Path, d.Message)
+}
+
+// Diagnostics are the problems found by ValidateConfig, in configuration order.
+type Diagnostics []Diagnostic
+
+// Errors returns the diagnostics that prevent the plugin from starting.
+func (d Diagnostics) Errors() Diagnostics {
+	return d.filter(SeverityError)
+}
+
+// Warnings returns the diagnostics about suspicious, but accepted, configurations.
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.filter(SeverityWarning)
+}
+
+// Err returns a single error listing every diagnostic when at least one of them is an error, otherwise nil.
+func (d Diagnostics) Err() error {
+	if len(d.Errors()) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(d))
+	for _, diagnostic := range d {
+		lines = append(lines, "  "+diagnostic.String())
+	}
+
+	return fmt.Errorf("validate-headers: configuration incorrect:\n%s", strings.Join(lines, "\n"))
+}
+
+func (d Diagnostics) filter(severity Severity) Diagnostics {
+	var filtered Diagnostics
+
+	for _, diagnostic := range d {
+		if diagnostic.Severity == severity {
+			filtered = append(filtered, diagnostic)
+		}
+	}
+
+	return filtered
+}
+
+func (d *Diagnostics) errorf(path string, format string, args ...interface{}) {
+	*d = append(*d, Diagnostic{Severity: SeverityError, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// add records an error returned by a validation.
+func (d *Diagnostics) add(path string, err error) {
+	d.errorf(path, "%v", err)
+}
+
+func (d *Diagnostics) warnf(path string, format string, args ...interface{}) {
+	*d = append(*d, Diagnostic{Severity: SeverityWarning, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// errEmptyValue is returned by validateValue for a blank value.
+var errEmptyValue = errors.New("empty value found")
+
+// validateValue checks a single configured value of the header: it must not be blank and it must be
+// a valid glob or media range when the header uses that match mode.
+func validateValue(vHeader *SingleHeader, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errEmptyValue
+	}
+
+	var err error
+
+	switch {
+	case vHeader.IsGlob():
+		_, err = compileGlob(value)
+	case vHeader.IsMediaType():
+		_, err = parseMediaRange(value)
+	}
+
+	return err
+}
+
+// ValidateConfig checks the complete configuration and returns every problem, with the path of its setting,
+// together with warnings for configurations that are accepted but likely unintended. The configuration is not
+// modified. New rejects a configuration with errors with Diagnostics.Err, which lists all problems.
+func ValidateConfig(config *Config) Diagnostics {
+	var diagnostics Diagnostics
+
+	if len(config.Headers) == 0 && len(config.Rules) == 0 && !config.Digest.IsEnabled() {
+		diagnostics.errorf("headers", "missing headers")
+	}
+
+	if config.MaxHeaderCount < 0 {
+		diagnostics.errorf("maxheadercount", "must not be negative")
+	}
+
+	if config.MaxHeaderBytes < 0 {
+		diagnostics.errorf("maxheaderbytes", "must not be negative")
+	}
+
+	if config.Digest.MaxBodySize < 0 {
+		diagnostics.errorf("digest.maxbodysize", "must not be negative")
+	}
+
+	if err := validateNameResolution(config.NameResolution); err != nil {
+		diagnostics.add("nameresolution", err)
+	}
+
+	matchType := string(MatchAll)
+
+	if strings.TrimSpace(config.MatchType) != "" {
+		parsed, err := ParseMatchType(config.MatchType, configMatchTypes)
+		if err != nil {
+			diagnostics.errorf("matchtype", "%v", err)
+		} else {
+			matchType = string(parsed)
+		}
+	}
+
+	scored := *config
+	scored.MatchType = matchType
+
+	if err := validateThreshold(&scored); err != nil {
+		diagnostics.errorf("threshold", "%v", err)
+	}
+
+	checked := 0
+	names := make(map[string]string)
+
+	for i := range config.Headers {
+		path := fmt.Sprintf("headers[%d]", i)
+
+		// compileHeader normalizes and compiles the header, so it works on a copy.
+		vHeader := copyHeader(&config.Headers[i])
+		diagnostics = append(diagnostics, validateHeader(path, &vHeader, config.NameResolution)...)
+
+		if err := validateWeight(matchType, &vHeader); err != nil {
+			diagnostics.errorf(path+".weight", "%v", err)
+		}
+
+		if matchType == string(MatchScore) || (!vHeader.IsAbsent() && vHeader.NamePattern == "") {
+			checked++
+		}
+
+		if matchType == string(MatchNone) && vHeader.MatchType == string(MatchNone) {
+			diagnostics.warnf(path+".matchtype", "none inside the plugin match type none is a double negation, the header has to match one of the values")
+		}
+
+		if vHeader.Name == "" {
+			continue
+		}
+
+		key := http.CanonicalHeaderKey(vHeader.Name)
+		if !isExactNameResolution(config.NameResolution) {
+			key = http.CanonicalHeaderKey(normalizeName(vHeader.Name))
+		}
+
+		if first, ok := names[key]; ok {
+			diagnostics.warnf(path+".name", "header %s is also configured in %s", vHeader.Name, first)
+		} else {
+			names[key] = path
+		}
+	}
+
+	if err := validateCount(matchType, config.Count, checked); err != nil {
+		diagnostics.errorf("count", "%v", err)
+	}
+
+	for i := range config.Rules {
+		diagnostics = append(diagnostics, validateRule(fmt.Sprintf("rules[%d]", i), &config.Rules[i], config.NameResolution)...)
+	}
+
+	return diagnostics
+}
+
+// validateRule returns the problems of a conditional rule and its headers.
+func validateRule(path string, rule *ConditionalRule, nameResolution string) Diagnostics {
+	var diagnostics Diagnostics
+
+	if len(rule.If) == 0 {
+		diagnostics.errorf(path+".if", "missing headers")
+	}
+
+	if len(rule.Then) == 0 && len(rule.Else) == 0 {
+		diagnostics.errorf(path, "missing 'then' or 'else' headers")
+	}
+
+	branches := []struct {
+		name    string
+		headers []SingleHeader
+	}{
+		{name: "if", headers: rule.If},
+		{name: "then", headers: rule.Then},
+		{name: "else", headers: rule.Else},
+	}
+
+	for _, branch := range branches {
+		for j := range branch.headers {
+			headerPath := fmt.Sprintf("%s.%s[%d]", path, branch.name, j)
+
+			vHeader := copyHeader(&branch.headers[j])
+			if branch.name == "if" && vHeader.IsStrip() {
+				diagnostics.errorf(headerPath+".strip", "strip can't be used in the 'if' headers")
+			}
+
+			diagnostics = append(diagnostics, validateHeader(headerPath, &vHeader, nameResolution)...)
+		}
+	}
+
+	return diagnostics
+}
+
+// validateHeader compiles the header and returns its problems, with warnings for suspicious settings.
+func validateHeader(path string, vHeader *SingleHeader, nameResolution string) Diagnostics {
+	diagnostics := compileHeader(path, vHeader, nameResolution)
+
+	if vHeader.IsRegex() {
+		for j, value := range vHeader.Values {
+			if _, err := regexp.Compile(value); err != nil {
+				diagnostics.warnf(fmt.Sprintf("%s.values[%d]", path, j), "regex never matches, %v", err)
+			} else if !isAnchored(value) {
+				diagnostics.warnf(fmt.Sprintf("%s.values[%d]", path, j), "regex %q is not anchored, it also matches values that only contain a match; use ^ and $ to match the whole value", value)
+			}
+		}
+	}
+
+	if vHeader.IsContains() && !vHeader.IsRequired() && vHeader.MatchType == string(MatchNone) {
+		diagnostics.warnf(path, "contains with match type none and required false only rejects values that contain one of the values, requests without the header pass")
+	}
+
+	return diagnostics
+}
+
+// copyHeader copies the header with its own slices, so compiling the copy never modifies the configuration.
+func copyHeader(vHeader *SingleHeader) SingleHeader {
+	copied := *vHeader
+	copied.Values = append([]string(nil), vHeader.Values...)
+	copied.Names = append([]string(nil), vHeader.Names...)
+	copied.Transforms = append([]string(nil), vHeader.Transforms...)
+
+	return copied
+}
+
+// regexFlags matches the flags at the start of a regex, e.g. `(?i)`.
+var regexFlags = regexp.MustCompile(`^\(\?[imsU]+\)`)
+
+// isAnchored checks whether a regex, after its leading flags, is anchored at the start and the end of the value.
+func isAnchored(expr string) bool {
+	expr = regexFlags.ReplaceAllString(expr, "")
+
+	return (strings.HasPrefix(expr, "^") || strings.HasPrefix(expr, `\A`)) &&
+		(strings.HasSuffix(expr, "$") || strings.HasSuffix(expr, `\z`))
+}
@@ -0,0 +1,247 @@
+package traefik_plugin_validate_headers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		expected []string
+	}{
+		{
+			name: "Valid",
+			config: &Config{
+				MatchType: "ANY",
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Api-Key",
+						MatchType: string(MatchOne),
+						Values:    []string{"secret"},
+					},
+					{
+						Name:      "Content-Language",
+						MatchType: string(MatchOne),
+						Values:    []string{"(?i)^de-[a-z]{2}$"},
+						Regex:     Bool(true),
+					},
+				},
+			},
+		},
+		{
+			name: "MissingHeaders",
+			config: &Config{
+				MaxHeaderCount: -1,
+			},
+			expected: []string{
+				"error: headers: missing headers",
+				"error: maxheadercount: must not be negative",
+			},
+		},
+		{
+			name: "EveryProblem",
+			config: &Config{
+				MatchType:      "every",
+				NameResolution: "loose",
+				Threshold:      3,
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Api-Key",
+						MatchType: "nnone",
+						Values:    []string{"secret"},
+					},
+					{
+						Name:      "Content-Type",
+						MatchType: string(MatchOne),
+						Values:    []string{"application/json", " ", "text"},
+						MediaType: Bool(true),
+					},
+					{
+						Name:      "Content-Language",
+						MatchType: "None",
+						Values:    []string{"de"},
+						Contains:  Bool(true),
+						Regex:     Bool(true),
+					},
+					{
+						Name:      "X-API-KEY",
+						MatchType: string(MatchOne),
+						Values:    []string{"other"},
+						Weight:    2,
+					},
+				},
+				Rules: []ConditionalRule{
+					{
+						If: []SingleHeader{
+							{
+								Name:   "X-Internal",
+								Absent: Bool(true),
+								Strip:  Bool(true),
+							},
+						},
+					},
+				},
+			},
+			expected: []string{
+				`error: nameresolution: unknown nameresolution "loose", allowed: exact, treat-underscore-as-dash, reject-ambiguous`,
+				`error: matchtype: unknown matchtype "every", allowed: all, one, none, atleast, atmost, exactly, score`,
+				"error: threshold: threshold and scoreheader can only be used in combination with match type score",
+				`error: headers[0].matchtype: unknown matchtype "nnone", allowed: all, one, none, atleast, atmost, exactly`,
+				"error: headers[1].values[1]: empty value found",
+				`error: headers[1].values[2]: invalid media type "text", expected type/subtype`,
+				"error: headers[2]: only one of 'contains', 'regex', 'glob', 'prefix', 'suffix', 'mediatype' or 'language' can be used, found contains, regex",
+				"warning: headers[2].values[0]: regex \"de\" is not anchored, it also matches values that only contain a match; use ^ and $ to match the whole value",
+				"error: headers[3].weight: weight can only be used in combination with match type score",
+				"warning: headers[3].name: header X-API-KEY is also configured in headers[0]",
+				"error: rules[0]: missing 'then' or 'else' headers",
+				"error: rules[0].if[0].strip: strip can't be used in the 'if' headers",
+			},
+		},
+		{
+			name: "EveryProblemOfAHeader",
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:          "X-Request-Id",
+						MatchType:     "every",
+						Values:        []string{"a", ""},
+						Charset:       "latin1",
+						URLDecodeMode: "twice",
+						Transforms:    []string{"rot13"},
+					},
+				},
+			},
+			expected: []string{
+				`error: headers[0].matchtype: unknown matchtype "every", allowed: all, one, none, atleast, atmost, exactly`,
+				`error: headers[0].charset: unknown charset "latin1", allowed: ascii-printable, token, base64, hex, uuid`,
+				`error: headers[0].urldecodemode: unknown urldecodemode "twice", allowed: strict, repeat, reject-double`,
+				`error: headers[0].transforms: unknown transform "rot13"`,
+				"error: headers[0].values[1]: empty value found",
+			},
+		},
+		{
+			name: "EveryProblemOfAnAbsentHeader",
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						NamePattern: "X-Debug-*",
+						MinCount:    1,
+						Values:      []string{"on"},
+						Absent:      Bool(true),
+					},
+				},
+			},
+			expected: []string{
+				"error: headers[0].namepattern: mincount must be positive and can't be used in combination with absent",
+				"error: headers[0]: absent can't be combined with values, match modes, constraints, transforms or a source",
+			},
+		},
+		{
+			name: "Warnings",
+			config: &Config{
+				MatchType:      string(MatchNone),
+				NameResolution: NameResolutionUnderscoreAsDash,
+				Headers: []SingleHeader{
+					{
+						Name:      "X_User",
+						MatchType: string(MatchNone),
+						Values:    []string{"admin"},
+						Contains:  Bool(true),
+						Required:  Bool(false),
+					},
+					{
+						Name:      "X-User",
+						MatchType: string(MatchOne),
+						Values:    []string{"^admin$", "["},
+						Regex:     Bool(true),
+					},
+				},
+			},
+			expected: []string{
+				"warning: headers[0]: contains with match type none and required false only rejects values that contain one of the values, requests without the header pass",
+				"warning: headers[0].matchtype: none inside the plugin match type none is a double negation, the header has to match one of the values",
+				"warning: headers[1].values[1]: regex never matches, error parsing regexp: missing closing ]: `[`",
+				"warning: headers[1].name: header X-User is also configured in headers[0]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := ValidateConfig(tt.config)
+
+			actual := make([]string, 0, len(diagnostics))
+			for _, diagnostic := range diagnostics {
+				actual = append(actual, diagnostic.String())
+			}
+
+			if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(actual, "\n"), strings.Join(tt.expected, "\n"))
+			}
+
+			if (diagnostics.Err() == nil) != (len(diagnostics.Errors()) == 0) {
+				t.Errorf("Err() = %v with %d errors", diagnostics.Err(), len(diagnostics.Errors()))
+			}
+		})
+	}
+}
+
+func TestValidateConfigDoesNotModify(t *testing.T) {
+	config := &Config{
+		MatchType: "ANY",
+		Headers: []SingleHeader{
+			{
+				Name:      "X-Api-Key",
+				MatchType: "One",
+				Values:    []string{"secret"},
+			},
+			{
+				Name:      "Priority",
+				MatchType: string(MatchOne),
+				Values:    []string{"01"},
+				Source:    SourceSFDictionary,
+				Path:      "u",
+			},
+		},
+		Rules: []ConditionalRule{
+			{
+				If:   []SingleHeader{{Name: "X-Client", MatchType: "One", Values: []string{"partner"}}},
+				Then: []SingleHeader{{Name: "Priority", MatchType: string(MatchOne), Values: []string{"01"}, Source: SourceSFDictionary, Path: "u"}},
+			},
+		},
+	}
+
+	if err := ValidateConfig(config).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.MatchType != "ANY" || config.Headers[0].MatchType != "One" || config.Error.StatusCode != 0 {
+		t.Errorf("configuration was modified: %+v", config)
+	}
+
+	if config.Headers[1].Values[0] != "01" || config.Rules[0].Then[0].Values[0] != "01" || config.Rules[0].If[0].MatchType != "One" {
+		t.Errorf("headers were modified: %+v, rules: %+v", config.Headers, config.Rules)
+	}
+}
+
+func TestDiagnosticsErr(t *testing.T) {
+	diagnostics := ValidateConfig(&Config{
+		Headers: []SingleHeader{
+			{
+				Name:      "X-Api-Key",
+				MatchType: string(MatchOne),
+				Values:    []string{"", "secret", ""},
+			},
+		},
+	})
+
+	expected := "validate-headers: configuration incorrect:\n" +
+		"  error: headers[0].values[0]: empty value found\n" +
+		"  error: headers[0].values[2]: empty value found"
+
+	if err := diagnostics.Err(); err == nil || err.Error() != expected {
+		t.Errorf("got %v, want %s", err, expected)
+	}
+}
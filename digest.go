@@ -0,0 +1,223 @@
+package traefik_plugin_validate_headers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultDigestMaxBodySize is the largest body that is buffered for digest verification by default (1 MiB).
+const defaultDigestMaxBodySize int64 = 1 << 20
+
+// DigestConfig configures the verification of the request body against the Content-Digest or Digest header.
+type DigestConfig struct {
+	Enabled     *bool `json:"enabled,omitempty"`
+	Required    *bool `json:"required,omitempty"`
+	MaxBodySize int64 `json:"maxbodysize,omitempty"`
+	Debug       *bool `json:"debug,omitempty"`
+}
+
+// errBodyTooLarge is returned when the request body exceeds the size that can be buffered.
+var errBodyTooLarge = errors.New("body exceeds maximum size")
+
+// digestAlgorithms maps the supported digest algorithm names (lowercase) to their hash constructors.
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-256": sha256.New,
+	"sha-512": sha512.New,
+}
+
+// digestEntry is a single algorithm/digest pair taken from a digest header.
+type digestEntry struct {
+	algorithm string
+	digest    []byte
+}
+
+// checkDigest verifies the request body against the digests announced in the Content-Digest and Digest headers.
+// The body is buffered up to the configured maximum size and restored afterwards, so the next handler can read it.
+func checkDigest(config *DigestConfig, req *http.Request) *Failure {
+	contentDigest := req.Header.Values("Content-Digest")
+	legacyDigest := req.Header.Values("Digest")
+
+	if len(contentDigest) == 0 && len(legacyDigest) == 0 {
+		if config.IsDebug() {
+			fmt.Println("validate-headers (debug): No digest header found, required:", config.IsRequired())
+		}
+
+		if config.IsRequired() {
+			return &Failure{Header: "Content-Digest", Reason: ReasonMissing}
+		}
+
+		return nil
+	}
+
+	var entries []digestEntry
+
+	for _, value := range contentDigest {
+		parsed, err := parseContentDigest(value)
+		if err != nil {
+			return digestFailure(config, "Content-Digest", ReasonMalformed, err)
+		}
+
+		entries = append(entries, parsed...)
+	}
+
+	for _, value := range legacyDigest {
+		parsed, err := parseLegacyDigest(value)
+		if err != nil {
+			return digestFailure(config, "Digest", ReasonMalformed, err)
+		}
+
+		entries = append(entries, parsed...)
+	}
+
+	if len(entries) == 0 {
+		return digestFailure(config, "Content-Digest", ReasonMalformed, fmt.Errorf("no supported digest algorithm found"))
+	}
+
+	body, err := readBody(req, config.MaxBodySize)
+	if errors.Is(err, errBodyTooLarge) {
+		return digestFailure(config, "", ReasonBodyTooLarge, err)
+	}
+
+	if err != nil {
+		return digestFailure(config, "", ReasonMalformed, err)
+	}
+
+	for _, entry := range entries {
+		h := digestAlgorithms[entry.algorithm]()
+		h.Write(body)
+
+		if !bytes.Equal(h.Sum(nil), entry.digest) {
+			return digestFailure(config, "", ReasonDigestMismatch, fmt.Errorf("digest mismatch for algorithm %s", entry.algorithm))
+		}
+	}
+
+	return nil
+}
+
+// digestFailure creates a failure for the digest verification and prints it when debugging is enabled.
+func digestFailure(config *DigestConfig, header string, reason Reason, err error) *Failure {
+	if config.IsDebug() {
+		fmt.Println("validate-headers (debug): ERROR verifying digest:", err)
+	}
+
+	return &Failure{Header: header, Reason: reason, Detail: err.Error()}
+}
+
+// readBody buffers the request body up to maxSize bytes and replaces req.Body with a reader over the buffered content.
+func readBody(req *http.Request, maxSize int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return []byte{}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("%w of %d bytes", errBodyTooLarge, maxSize)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// parseContentDigest parses a Content-Digest header (RFC 9530), e.g. `sha-256=:base64:, sha-512=:base64:`.
+// Entries with unsupported algorithms are skipped.
+func parseContentDigest(value string) ([]digestEntry, error) {
+	var entries []digestEntry
+
+	for _, member := range strings.Split(value, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+
+		key, raw, found := strings.Cut(member, "=")
+		if !found {
+			return nil, fmt.Errorf("missing value in member %q", member)
+		}
+
+		algorithm := strings.ToLower(strings.TrimSpace(key))
+
+		// The value is a byte sequence (`:base64:`), optionally followed by parameters.
+		raw = strings.TrimSpace(raw)
+		if !strings.HasPrefix(raw, ":") {
+			return nil, fmt.Errorf("value for %q is not a byte sequence", algorithm)
+		}
+
+		end := strings.Index(raw[1:], ":")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated byte sequence for %q", algorithm)
+		}
+
+		if _, ok := digestAlgorithms[algorithm]; !ok {
+			continue
+		}
+
+		digest, err := base64.StdEncoding.DecodeString(raw[1 : end+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 for %q: %w", algorithm, err)
+		}
+
+		entries = append(entries, digestEntry{algorithm: algorithm, digest: digest})
+	}
+
+	return entries, nil
+}
+
+// parseLegacyDigest parses a legacy Digest header (RFC 3230), e.g. `SHA-256=base64, SHA-512=base64`.
+// Algorithm names are case-insensitive and entries with unsupported algorithms are skipped.
+func parseLegacyDigest(value string) ([]digestEntry, error) {
+	var entries []digestEntry
+
+	for _, member := range strings.Split(value, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+
+		key, raw, found := strings.Cut(member, "=")
+		if !found {
+			return nil, fmt.Errorf("missing value in member %q", member)
+		}
+
+		algorithm := strings.ToLower(strings.TrimSpace(key))
+		if _, ok := digestAlgorithms[algorithm]; !ok {
+			continue
+		}
+
+		digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 for %q: %w", algorithm, err)
+		}
+
+		entries = append(entries, digestEntry{algorithm: algorithm, digest: digest})
+	}
+
+	return entries, nil
+}
+
+// IsEnabled checks whether the request body should be verified against its digest header.
+func (d *DigestConfig) IsEnabled() bool {
+	return d.Enabled != nil && *d.Enabled
+}
+
+// IsRequired checks whether a digest header is mandatory when digest verification is enabled; defaults to 'false'.
+func (d *DigestConfig) IsRequired() bool {
+	return d.Required != nil && *d.Required
+}
+
+// IsDebug checks whether digest verification should print debug information in the log.
+func (d *DigestConfig) IsDebug() bool {
+	return d.Debug != nil && *d.Debug
+}
@@ -0,0 +1,283 @@
+package traefik_plugin_validate_headers
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type DigestTest struct {
+	name           string
+	body           string
+	headers        map[string]string
+	expectedStatus int
+}
+
+func sha256Base64(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func sha512Base64(body string) string {
+	sum := sha512.Sum512([]byte(body))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestDigest(t *testing.T) {
+	body := `{"hello": "world"}`
+
+	configTestPairs := []struct {
+		config *Config
+		tests  []DigestTest
+	}{
+		//DigestOptionalConfig
+		{
+			config: &Config{
+				Digest: DigestConfig{
+					Enabled: Bool(true),
+					Debug:   Bool(true),
+				},
+			},
+			tests: []DigestTest{
+				{
+					name: "ContentDigest_SHA256_Success",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:" + sha256Base64(body) + ":",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ContentDigest_SHA512_Success",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-512=:" + sha512Base64(body) + ":",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ContentDigest_Multiple_Success",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:" + sha256Base64(body) + ":, sha-512=:" + sha512Base64(body) + ":",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ContentDigest_UnsupportedIgnored_Success",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "md5=:AAAA:, sha-256=:" + sha256Base64(body) + ":",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ContentDigest_Mismatch_Fail",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:" + sha256Base64("tampered") + ":",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "ContentDigest_OneOfMultipleMismatch_Fail",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:" + sha256Base64(body) + ":, sha-512=:" + sha512Base64("tampered") + ":",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "ContentDigest_OnlyUnsupported_Fail",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "md5=:AAAA:",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "ContentDigest_NotByteSequence_Fail",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-256=" + sha256Base64(body),
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "ContentDigest_InvalidBase64_Fail",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:not base64!:",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "LegacyDigest_SHA256_Success",
+					body: body,
+					headers: map[string]string{
+						"Digest": "SHA-256=" + sha256Base64(body),
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "LegacyDigest_SHA512_Mismatch_Fail",
+					body: body,
+					headers: map[string]string{
+						"Digest": "SHA-512=" + sha512Base64("tampered"),
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "BothHeaders_LegacyMismatch_Fail",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:" + sha256Base64(body) + ":",
+						"Digest":         "SHA-256=" + sha256Base64("tampered"),
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name:           "NoDigestHeader_Success",
+					body:           body,
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "EmptyBody_Success",
+					body: "",
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:" + sha256Base64("") + ":",
+					},
+					expectedStatus: http.StatusOK,
+				},
+			},
+		},
+		//DigestRequiredConfig
+		{
+			config: &Config{
+				Digest: DigestConfig{
+					Enabled:     Bool(true),
+					Required:    Bool(true),
+					MaxBodySize: 8,
+				},
+			},
+			tests: []DigestTest{
+				{
+					name:           "DigestRequired_Missing_Fail",
+					body:           "small",
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "DigestRequired_Success",
+					body: "small",
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:" + sha256Base64("small") + ":",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "DigestRequired_BodyTooLarge_Fail",
+					body: body,
+					headers: map[string]string{
+						"Content-Digest": "sha-256=:" + sha256Base64(body) + ":",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		//DigestWithHeadersConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Content-Type",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"application/json",
+						},
+					},
+				},
+				Digest: DigestConfig{
+					Enabled: Bool(true),
+				},
+			},
+			tests: []DigestTest{
+				{
+					name: "DigestWithHeaders_Success",
+					body: body,
+					headers: map[string]string{
+						"Content-Type":   "application/json",
+						"Content-Digest": "sha-256=:" + sha256Base64(body) + ":",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "DigestWithHeaders_HeaderFail",
+					body: body,
+					headers: map[string]string{
+						"Content-Type":   "text/plain",
+						"Content-Digest": "sha-256=:" + sha256Base64(body) + ":",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+	}
+
+	for _, ct := range configTestPairs {
+		for _, tt := range ct.tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req, err := http.NewRequest("POST", "/", strings.NewReader(tt.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for key, value := range tt.headers {
+					req.Header.Add(key, value)
+				}
+
+				rr := httptest.NewRecorder()
+
+				var received string
+				next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					b, _ := io.ReadAll(r.Body)
+					received = string(b)
+					w.WriteHeader(http.StatusOK)
+				})
+
+				h, err := New(nil, next, ct.config, "test")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				h.ServeHTTP(rr, req)
+
+				if rr.Code != tt.expectedStatus {
+					t.Errorf("got %d, want %d", rr.Code, tt.expectedStatus)
+				}
+
+				if rr.Code == http.StatusOK && received != tt.body {
+					t.Errorf("next handler received body %q, want %q", received, tt.body)
+				}
+			})
+		}
+	}
+}
+
+func TestDigestConfig(t *testing.T) {
+	_, err := New(nil, http.HandlerFunc(dummyHandler), &Config{
+		Digest: DigestConfig{
+			Enabled:     Bool(true),
+			MaxBodySize: -1,
+		},
+	}, "test")
+
+	expected := "validate-headers: configuration incorrect:\n  error: digest.maxbodysize: must not be negative"
+	if err == nil || err.Error() != expected {
+		t.Errorf("got %v, want %s", err, expected)
+	}
+}
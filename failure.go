@@ -0,0 +1,66 @@
+package traefik_plugin_validate_headers
+
+import "fmt"
+
+// Reason identifies why a request failed validation.
+type Reason string
+
+const (
+	// ReasonMissing indicates that a required header is not present.
+	ReasonMissing Reason = "missing"
+	// ReasonMismatch indicates that a header value does not match the configured values.
+	ReasonMismatch Reason = "mismatch"
+	// ReasonTooShort indicates that a header value is shorter than the configured minimum length.
+	ReasonTooShort Reason = "too-short"
+	// ReasonTooLong indicates that a header value is longer than the configured maximum length.
+	ReasonTooLong Reason = "too-long"
+	// ReasonInvalidCharset indicates that a header value contains characters outside the configured charset.
+	ReasonInvalidCharset Reason = "invalid-charset"
+	// ReasonTooManyHeaders indicates that the request carries more headers than allowed.
+	ReasonTooManyHeaders Reason = "too-many-headers"
+	// ReasonHeadersTooLarge indicates that the request headers exceed the allowed total size.
+	ReasonHeadersTooLarge Reason = "headers-too-large"
+	// ReasonForbidden indicates that a header that must be absent is present.
+	ReasonForbidden Reason = "forbidden"
+	// ReasonAmbiguous indicates that a header is present with more than one equivalent spelling.
+	ReasonAmbiguous Reason = "ambiguous"
+	// ReasonMalformed indicates that a value could not be parsed.
+	ReasonMalformed Reason = "malformed"
+	// ReasonRiskScore indicates that the risk score of the request is above the configured threshold.
+	ReasonRiskScore Reason = "risk-score"
+	// ReasonDigestMismatch indicates that the request body does not match its digest header.
+	ReasonDigestMismatch Reason = "digest-mismatch"
+	// ReasonBodyTooLarge indicates that the request body exceeds the size that can be buffered.
+	ReasonBodyTooLarge Reason = "body-too-large"
+)
+
+// Failure describes the rule that caused a request to be rejected.
+type Failure struct {
+	Header string
+	Reason Reason
+	Detail string
+}
+
+// Error returns a human readable description of the failure.
+func (f *Failure) Error() string {
+	msg := string(f.Reason)
+
+	if f.Header != "" {
+		msg = fmt.Sprintf("header %s: %s", f.Header, msg)
+	}
+
+	if f.Detail != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, f.Detail)
+	}
+
+	return msg
+}
+
+// summary returns the short form of the failure that is exposed in the reason response header.
+func (f *Failure) summary() string {
+	if f.Header == "" {
+		return string(f.Reason)
+	}
+
+	return f.Header + ": " + string(f.Reason)
+}
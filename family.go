@@ -0,0 +1,104 @@
+package traefik_plugin_validate_headers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+)
+
+// compileNamePattern validates the name pattern of a header family and compiles it into a case-insensitive regexp.
+func compileNamePattern(vHeader *SingleHeader) error {
+	if vHeader.NamePattern == "" {
+		if vHeader.IsNameRegex() || vHeader.MinCount != 0 {
+			return errors.New("nameregex and mincount can only be used in combination with namepattern")
+		}
+
+		return nil
+	}
+
+	if vHeader.Name != "" || len(vHeader.Names) > 0 {
+		return errors.New("name or names can't be used in combination with namepattern")
+	}
+
+	if vHeader.MinCount < 0 || (vHeader.MinCount > 0 && vHeader.IsAbsent()) {
+		return errors.New("mincount must be positive and can't be used in combination with absent")
+	}
+
+	expr := vHeader.NamePattern
+
+	if !vHeader.IsNameRegex() {
+		glob, err := compileGlob(vHeader.NamePattern)
+		if err != nil {
+			return err
+		}
+
+		expr = glob.String()
+	}
+
+	namePattern, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return err
+	}
+
+	vHeader.namePattern = namePattern
+
+	return nil
+}
+
+// matchingNames returns the sorted names of the request headers that match the name pattern. Unless the
+// name resolution is exact, underscores in the names are matched as dashes.
+func matchingNames(namePattern *regexp.Regexp, req *http.Request, nameResolution string) []string {
+	var names []string
+
+	for name := range req.Header {
+		normalized := name
+		if !isExactNameResolution(nameResolution) {
+			normalized = normalizeName(name)
+		}
+
+		if namePattern.MatchString(normalized) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// checkFamily checks every request header that matches the name pattern as if it was configured by name,
+// after checking that at least 'mincount' of them are present.
+func checkFamily(vHeader *SingleHeader, req *http.Request) *Failure {
+	names := matchingNames(vHeader.namePattern, req, vHeader.nameResolution)
+
+	if vHeader.IsDebug() {
+		fmt.Println("validate-headers (debug): Validating headers matching", vHeader.NamePattern+":", names)
+	}
+
+	if len(names) < vHeader.MinCount {
+		return &Failure{Header: vHeader.NamePattern, Reason: ReasonMissing, Detail: fmt.Sprintf("found %d of at least %d matching headers", len(names), vHeader.MinCount)}
+	}
+
+	for _, name := range names {
+		member := *vHeader
+		member.Name = name
+		member.namePattern = nil
+
+		if failure := checkHeader(&member, req); failure != nil {
+			return failure
+		}
+	}
+
+	return nil
+}
+
+// displayName returns the name of the header, or its name pattern for a header family.
+func (s *SingleHeader) displayName() string {
+	if s.Name == "" {
+		return s.NamePattern
+	}
+
+	return s.Name
+}
@@ -0,0 +1,205 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHeaderFamily(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//CustomFamilyConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						NamePattern: "X-Custom-*",
+						MaxLength:   16,
+						Charset:     CharsetASCIIPrintable,
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "CustomFamily_Success",
+					headers: map[string]string{
+						"X-Custom-A": "short",
+						"X-Custom-B": "also short",
+						"X-Other":    "this one is not part of the family",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name:           "CustomFamily_Success_Nonepresent",
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "CustomFamily_Fail_TooLong",
+					headers: map[string]string{
+						"X-Custom-A": "short",
+						"X-Custom-B": "this value is too long",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Custom-B: too-long",
+				},
+				{
+					name: "CustomFamily_Fail_Charset",
+					headers: map[string]string{
+						"x-custom-c": "café",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Custom-C: invalid-charset",
+				},
+			},
+		},
+		//DebugFamilyConfig
+		{
+			config: &Config{
+				MatchType: string(MatchOne),
+				Headers: []SingleHeader{
+					{
+						NamePattern: "^X-Debug-",
+						NameRegex:   Bool(true),
+						Absent:      Bool(true),
+					},
+					{
+						Name:      "X-Api-Key",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"secret",
+						},
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "DebugFamily_Success",
+					headers: map[string]string{
+						"X-Api-Key":    "secret",
+						"X-Debugger":   "not a debug header",
+						"X-Trace-Id":   "abc",
+						"X-Not-Debug-": "neither",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "DebugFamily_Fail_Present",
+					headers: map[string]string{
+						"X-Api-Key":     "secret",
+						"X-Debug-Level": "trace",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Debug-Level: forbidden",
+				},
+			},
+		},
+		//MinCountFamilyConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						NamePattern: "X-Signature-?",
+						MatchType:   string(MatchOne),
+						Values: []string{
+							"sha256=",
+						},
+						Prefix:   Bool(true),
+						MinCount: 2,
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "MinCountFamily_Success",
+					headers: map[string]string{
+						"X-Signature-1": "sha256=abc",
+						"X-Signature-2": "sha256=def",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MinCountFamily_Fail_TooFew",
+					headers: map[string]string{
+						"X-Signature-1": "sha256=abc",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Signature-?: missing",
+				},
+				{
+					name: "MinCountFamily_Fail_OneMismatch",
+					headers: map[string]string{
+						"X-Signature-1": "sha256=abc",
+						"X-Signature-2": "md5=def",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Signature-2: mismatch",
+				},
+			},
+		},
+		// NameAndNamePattern
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:        "X-Custom-A",
+						NamePattern: "X-Custom-*",
+						MaxLength:   16,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "NameAndNamePattern",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].namepattern: name or names can't be used in combination with namepattern"),
+				},
+			},
+		},
+		// InvalidNameRegex
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						NamePattern: "^X-(",
+						NameRegex:   Bool(true),
+						Absent:      Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "InvalidNameRegex",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].namepattern: error parsing regexp: missing closing ): `(?i)^X-(`"),
+				},
+			},
+		},
+		// MinCountWithoutNamePattern
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Custom-A",
+						MaxLength: 16,
+						MinCount:  1,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "MinCountWithoutNamePattern",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].namepattern: nameregex and mincount can only be used in combination with namepattern"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
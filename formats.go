@@ -0,0 +1,181 @@
+package traefik_plugin_validate_headers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	uuidRegex            = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	ulidRegex            = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+	traceparentRegex     = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})(-.*)?$`)
+	tracestateKeyRegex   = regexp.MustCompile(`^([a-z0-9][a-z0-9_\-*/]{0,255}|[a-z0-9][a-z0-9_\-*/]{0,240}@[a-z][a-z0-9_\-*/]{0,13})$`)
+	tracestateValueRegex = regexp.MustCompile(`^[\x20-\x2b\x2d-\x3c\x3e-\x7e]{0,255}[\x21-\x2b\x2d-\x3c\x3e-\x7e]$`)
+)
+
+// formats maps the supported format names to their validation functions.
+var formats = map[string]func(string) bool{
+	"uuid":    func(value string) bool { return uuidRegex.MatchString(value) },
+	"uuidv1":  uuidVersion('1'),
+	"uuidv2":  uuidVersion('2'),
+	"uuidv3":  uuidVersion('3'),
+	"uuidv4":  uuidVersion('4'),
+	"uuidv5":  uuidVersion('5'),
+	"uuidv6":  uuidVersion('6'),
+	"uuidv7":  uuidVersion('7'),
+	"uuidv8":  uuidVersion('8'),
+	"ulid":    func(value string) bool { return ulidRegex.MatchString(value) },
+	"email":   isEmail,
+	"ip":      func(value string) bool { return net.ParseIP(value) != nil },
+	"ipv4":    isIPv4,
+	"ipv6":    func(value string) bool { return strings.Contains(value, ":") && net.ParseIP(value) != nil },
+	"cidr":    isCIDR,
+	"rfc3339": isRFC3339,
+	"iso8601": isRFC3339,
+	"base64":  func(value string) bool { return decodes(base64.StdEncoding, value) },
+	"base64url": func(value string) bool {
+		return decodes(base64.URLEncoding, value) || decodes(base64.RawURLEncoding, value)
+	},
+	"hex":             isHex,
+	"traceparent":     isTraceparent,
+	"tracestate":      isTracestate,
+	"idempotency-key": isIdempotencyKey,
+}
+
+// formatNames returns the sorted names of the supported formats.
+func formatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// uuidVersion returns a validator for UUIDs of the given version.
+func uuidVersion(version byte) func(string) bool {
+	return func(value string) bool {
+		return uuidRegex.MatchString(value) && value[14] == version
+	}
+}
+
+// isEmail checks whether value is a bare email address, without display name or angle brackets.
+func isEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	return err == nil && addr.Name == "" && addr.Address == value
+}
+
+// isIPv4 checks whether value is an IPv4 address in dotted decimal notation.
+func isIPv4(value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && !strings.Contains(value, ":") && ip.To4() != nil
+}
+
+// isCIDR checks whether value is an IPv4 or IPv6 network in CIDR notation.
+func isCIDR(value string) bool {
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+// isRFC3339 checks whether value is an RFC 3339 timestamp, with optional fractional seconds.
+func isRFC3339(value string) bool {
+	_, err := time.Parse(time.RFC3339Nano, value)
+	return err == nil
+}
+
+// decodes checks whether value is non-empty and can be decoded with the given base64 encoding.
+func decodes(encoding *base64.Encoding, value string) bool {
+	_, err := encoding.DecodeString(value)
+	return value != "" && err == nil
+}
+
+// isHex checks whether value is a non-empty string of hexadecimal byte pairs.
+func isHex(value string) bool {
+	_, err := hex.DecodeString(value)
+	return value != "" && err == nil
+}
+
+// isTraceparent checks whether value is a W3C Trace Context traceparent header.
+func isTraceparent(value string) bool {
+	parts := traceparentRegex.FindStringSubmatch(value)
+	if parts == nil {
+		return false
+	}
+
+	version, traceID, parentID, extra := parts[1], parts[2], parts[3], parts[5]
+
+	// Version ff is invalid and version 00 doesn't allow additional fields.
+	if version == "ff" || (version == "00" && extra != "") {
+		return false
+	}
+
+	return strings.Trim(traceID, "0") != "" && strings.Trim(parentID, "0") != ""
+}
+
+// isTracestate checks whether value is a W3C Trace Context tracestate header.
+func isTracestate(value string) bool {
+	members := 0
+	keys := map[string]bool{}
+
+	for _, member := range strings.Split(value, ",") {
+		member = strings.Trim(member, " \t")
+		if member == "" {
+			continue
+		}
+
+		key, val, found := strings.Cut(member, "=")
+		if !found || !tracestateKeyRegex.MatchString(key) || !tracestateValueRegex.MatchString(val) || keys[key] {
+			return false
+		}
+
+		keys[key] = true
+		members++
+	}
+
+	return members > 0 && members <= 32
+}
+
+// isIdempotencyKey checks whether value is an Idempotency-Key, a Structured Field string with 1 to 255 characters.
+func isIdempotencyKey(value string) bool {
+	if len(value) < 3 || value[0] != '"' || value[len(value)-1] != '"' {
+		return false
+	}
+
+	key, ok := unescapeSFString(value[1 : len(value)-1])
+
+	return ok && len(key) > 0 && len(key) <= 255
+}
+
+// unescapeSFString unescapes the content of a Structured Field string (RFC 8941), without the surrounding quotes.
+// Only printable ASCII is allowed, and '"' and '\' must be escaped.
+func unescapeSFString(value string) (string, bool) {
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case c == '\\':
+			if i+1 >= len(value) || (value[i+1] != '"' && value[i+1] != '\\') {
+				return "", false
+			}
+
+			i++
+			sb.WriteByte(value[i])
+		case c == '"' || c < 0x20 || c > 0x7e:
+			return "", false
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), true
+}
@@ -0,0 +1,175 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		valid  bool
+	}{
+		{format: "uuid", value: "123e4567-e89b-12d3-a456-426614174000", valid: true},
+		{format: "uuid", value: "123e4567e89b12d3a456426614174000", valid: false},
+		{format: "uuid", value: "123e4567-e89b-12d3-a456-42661417400g", valid: false},
+		{format: "uuidv4", value: "9b2f4c7e-3d1a-4f6b-8c2d-1e5f7a9b3c4d", valid: true},
+		{format: "uuidv4", value: "123e4567-e89b-12d3-a456-426614174000", valid: false},
+		{format: "uuidv7", value: "01890a5d-ac96-774b-bcce-b302099a8057", valid: true},
+		{format: "ulid", value: "01ARZ3NDEKTSV4RRFFQ69G5FAV", valid: true},
+		{format: "ulid", value: "81ARZ3NDEKTSV4RRFFQ69G5FAV", valid: false},
+		{format: "ulid", value: "01ARZ3NDEKTSV4RRFFQ69G5FAU1", valid: false},
+		{format: "ulid", value: "01ARZ3NDEKTSV4RRFFQ69G5FAI", valid: false},
+		{format: "email", value: "jane.doe@example.com", valid: true},
+		{format: "email", value: "Jane <jane.doe@example.com>", valid: false},
+		{format: "email", value: "jane.doe", valid: false},
+		{format: "ip", value: "::1", valid: true},
+		{format: "ipv4", value: "192.168.1.10", valid: true},
+		{format: "ipv4", value: "::ffff:192.168.1.10", valid: false},
+		{format: "ipv4", value: "192.168.1.256", valid: false},
+		{format: "ipv6", value: "2001:db8::1", valid: true},
+		{format: "ipv6", value: "192.168.1.10", valid: false},
+		{format: "cidr", value: "10.0.0.0/8", valid: true},
+		{format: "cidr", value: "2001:db8::/32", valid: true},
+		{format: "cidr", value: "10.0.0.0", valid: false},
+		{format: "rfc3339", value: "2024-05-01T12:30:00Z", valid: true},
+		{format: "rfc3339", value: "2024-05-01T12:30:00.123+02:00", valid: true},
+		{format: "iso8601", value: "2024-05-01", valid: false},
+		{format: "base64", value: "aGVsbG8=", valid: true},
+		{format: "base64", value: "aGVsbG8", valid: false},
+		{format: "base64", value: "", valid: false},
+		{format: "base64url", value: "_-8", valid: true},
+		{format: "base64url", value: "_-8=", valid: true},
+		{format: "base64url", value: "+/8=", valid: false},
+		{format: "hex", value: "deadbeef", valid: true},
+		{format: "hex", value: "abc", valid: false},
+		{format: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", valid: true},
+		{format: "traceparent", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", valid: false},
+		{format: "traceparent", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", valid: false},
+		{format: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", valid: false},
+		{format: "traceparent", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", valid: false},
+		{format: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", valid: false},
+		{format: "traceparent", value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", valid: true},
+		{format: "tracestate", value: "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE", valid: true},
+		{format: "tracestate", value: "tenant@vendor=value", valid: true},
+		{format: "tracestate", value: "Rojo=00f067aa0ba902b7", valid: false},
+		{format: "tracestate", value: "rojo=1,rojo=2", valid: false},
+		{format: "tracestate", value: "rojo", valid: false},
+		{format: "idempotency-key", value: `"8e03978e-40d5-43e8-bc93-6894a57f9324"`, valid: true},
+		{format: "idempotency-key", value: `"key with \"quotes\""`, valid: true},
+		{format: "idempotency-key", value: `8e03978e-40d5-43e8-bc93-6894a57f9324`, valid: false},
+		{format: "idempotency-key", value: `""`, valid: false},
+		{format: "idempotency-key", value: `"` + strings.Repeat("a", 256) + `"`, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+"_"+tt.value, func(t *testing.T) {
+			if formats[tt.format](tt.value) != tt.valid {
+				t.Errorf("format %s on %q: got %v, want %v", tt.format, tt.value, !tt.valid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//FormatOnlyConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:   "Traceparent",
+						Format: "traceparent",
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "FormatOnly_Success",
+					headers: map[string]string{
+						"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "FormatOnly_Fail_Malformed",
+					headers: map[string]string{
+						"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Traceparent: malformed",
+				},
+			},
+		},
+		//FormatWithValuesConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Client-Ip",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"10.",
+							"192.168.",
+						},
+						Prefix: Bool(true),
+						Format: "ipv4",
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "FormatWithValues_Success",
+					headers: map[string]string{
+						"X-Client-Ip": "10.1.2.3",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "FormatWithValues_Fail_MalformedBeforeMatching",
+					headers: map[string]string{
+						"X-Client-Ip": "10.evil",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Client-Ip: malformed",
+				},
+				{
+					name: "FormatWithValues_Fail_Mismatch",
+					headers: map[string]string{
+						"X-Client-Ip": "8.8.8.8",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Client-Ip: mismatch",
+				},
+			},
+		},
+		// UnknownFormat
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:   "X-Request-Id",
+						Format: "guid",
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "UnknownFormat",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].format: unknown format \"guid\", allowed: base64, base64url, cidr, email, hex, idempotency-key, ip, ipv4, ipv6, iso8601, rfc3339, traceparent, tracestate, ulid, uuid, uuidv1, uuidv2, uuidv3, uuidv4, uuidv5, uuidv6, uuidv7, uuidv8"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
@@ -0,0 +1,184 @@
+package traefik_plugin_validate_headers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds are fuzz data for generatePolicy and generateRequest that cover every plugin match type.
+var fuzzSeeds = [][]byte{
+	{},
+	{0, 0, 0, 1, 0, 1, 0, 0, 1, 1},
+	{1, 2, 1, 0, 1, 2, 1, 1, 0, 2, 1, 1, 0, 1, 1, 0, 3, 1, 0, 1},
+	{2, 1, 2, 4, 0, 0, 2, 0, 1, 1, 3, 1, 1, 0, 1, 0, 2, 1, 1},
+	{0, 2, 0, 2, 1, 2, 1, 1, 1, 3, 0, 1, 2, 0, 1, 0, 0, 1, 1, 0, 1, 1},
+	{1, 1, 0, 3, 0, 2, 0, 0, 1, 1, 2, 4, 1, 0, 1, 0, 0, 2, 0, 1, 4, 1, 0, 0, 1},
+}
+
+// serveFuzz sends the request to a plugin with the configuration and returns the reason; empty when allowed.
+func serveFuzz(t *testing.T, config *Config, request map[string]string) string {
+	t.Helper()
+
+	handler, err := New(nil, http.HandlerFunc(dummyHandler), config, "fuzz")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for name, value := range request {
+		req.Header.Set(name, value)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	reason := rec.Header().Get("X-Reason")
+	if (rec.Code == http.StatusOK) != (reason == "") {
+		t.Fatalf("status %d with reason %q", rec.Code, reason)
+	}
+
+	return reason
+}
+
+// FuzzReferenceEvaluator checks the plugin against the reference evaluator for generated policies and requests.
+func FuzzReferenceEvaluator(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		in := &fuzzInput{data: data}
+		policy := generatePolicy(in)
+		request := generateRequest(in, policy)
+
+		expected := referenceEvaluate(policy, request)
+		if actual := serveFuzz(t, policy.config(), request); actual != expected {
+			t.Errorf("policy %+v with request %v: got reason %q, want %q", policy, request, actual, expected)
+		}
+	})
+}
+
+// FuzzValueOrder checks that the order of the configured values never changes the outcome.
+func FuzzValueOrder(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed, uint8(1))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, rotation uint8) {
+		in := &fuzzInput{data: data}
+		policy := generatePolicy(in)
+		request := generateRequest(in, policy)
+
+		expected := serveFuzz(t, policy.config(), request)
+
+		for i := range policy.headers {
+			values := policy.headers[i].values
+			shift := int(rotation) % len(values)
+			policy.headers[i].values = append(append([]string(nil), values[shift:]...), values[:shift]...)
+
+			if rotation%2 == 1 {
+				for l, r := 0, len(values)-1; l < r; l, r = l+1, r-1 {
+					policy.headers[i].values[l], policy.headers[i].values[r] = policy.headers[i].values[r], policy.headers[i].values[l]
+				}
+			}
+		}
+
+		if actual := serveFuzz(t, policy.config(), request); actual != expected {
+			t.Errorf("policy %+v with request %v: got reason %q after reordering the values, want %q", policy, request, actual, expected)
+		}
+	})
+}
+
+// FuzzNoneNegatesOne checks that, for a present header, the header match type 'none' rejects exactly the
+// values that 'one' allows, whatever the match mode and the plugin match type.
+func FuzzNoneNegatesOne(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		in := &fuzzInput{data: data}
+		policy := generatePolicy(in)
+		policy.headers = policy.headers[:1]
+
+		value := in.text(1, 4)
+		request := map[string]string{policy.headers[0].name: value}
+
+		policy.headers[0].matchType = MatchOne
+		one := serveFuzz(t, policy.config(), request)
+
+		policy.headers[0].matchType = MatchNone
+		none := serveFuzz(t, policy.config(), request)
+
+		if (one == "") == (none == "") {
+			t.Errorf("policy %+v with value %q: got reason %q for one and %q for none", policy, value, one, none)
+		}
+	})
+}
+
+// FuzzNew checks that New never panics on arbitrary header settings, that it accepts exactly the configurations
+// without errors from ValidateConfig, and that an accepted configuration never panics on a request.
+func FuzzNew(f *testing.F) {
+	f.Add(uint8(0), "X-Api-Key", "one", "secret", uint16(0), "", "", "secret")
+	f.Add(uint8(1), "Content-Language", "all", "de,DE", uint16(1), "", "", "de-DE")
+	f.Add(uint8(3), "X-Id", "atLeast", "^[a-f]+$,[0-9", uint16(2|1<<12), "list", "", "abc, 123")
+	f.Add(uint8(2), "Content-Type", "none", "application/*+json;charset=utf-8", uint16(32), "", "", "application/problem+json")
+	f.Add(uint8(0), "X-User", "one", "admin", uint16(0), "base64json", "$.groups", "eyJncm91cHMiOlsiYWRtaW4iXX0=")
+	f.Add(uint8(0), "Signature", "one", "sig1", uint16(0), "sfv-dictionary", "sig1;alg", "sig1=:YQ==:;alg=\"ed25519\"")
+	f.Add(uint8(0), "X-Tenant", "one", "{query:tenant},{header:X-Org}", uint16(0), "", "", "a")
+	f.Add(uint8(0), "X-Internal-*", "", "", uint16(1<<9|1<<10), "", "", "1")
+
+	f.Fuzz(func(t *testing.T, pluginMatchType uint8, name, matchType, values string, flags uint16, source, path, value string) {
+		vHeader := SingleHeader{
+			Name:      name,
+			MatchType: matchType,
+			Values:    strings.Split(values, ","),
+			Source:    source,
+			Path:      path,
+			Count:     int(flags >> 12),
+		}
+
+		settings := []**bool{
+			&vHeader.Contains, &vHeader.Regex, &vHeader.Glob, &vHeader.Prefix, &vHeader.Suffix,
+			&vHeader.MediaType, &vHeader.Language, &vHeader.Required, &vHeader.URLDecode, &vHeader.Absent, &vHeader.Strip,
+		}
+
+		for i, setting := range settings {
+			if flags&(1<<i) != 0 {
+				*setting = Bool(true)
+			}
+		}
+
+		if strings.ContainsAny(name, "*?[") {
+			vHeader.Name, vHeader.NamePattern = "", name
+		}
+
+		config := &Config{
+			MatchType: []string{"all", "one", "none", "atleast"}[pluginMatchType%4],
+			Headers:   []SingleHeader{vHeader},
+		}
+
+		if config.MatchType == string(MatchAtLeast) {
+			config.Count = 1
+		}
+
+		diagnosticsErr := ValidateConfig(config).Err()
+
+		handler, err := New(nil, http.HandlerFunc(dummyHandler), config, "fuzz")
+		if (err == nil) != (diagnosticsErr == nil) {
+			t.Fatalf("New returned %v, but ValidateConfig returned %v", err, diagnosticsErr)
+		}
+
+		if err != nil {
+			return
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/?tenant=a", nil)
+		req.Header[name] = []string{value}
+		req.Header["X-Org"] = []string{value}
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	})
+}
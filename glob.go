@@ -0,0 +1,96 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compileGlob translates a glob pattern into an anchored regular expression.
+// Supported syntax: '*' matches any sequence of characters, '?' matches a single character,
+// '[abc]', '[a-z]' and '[!abc]' (or '[^abc]') match character classes and '\' escapes the next character.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing escape character in glob %q", pattern)
+			}
+
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end, class, err := globClass(runes, i)
+			if err != nil {
+				return nil, fmt.Errorf("%w in glob %q", err, pattern)
+			}
+
+			i = end
+			sb.WriteString(class)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// globClass translates the character class starting at runes[start] ('[') into its regular expression form.
+// It returns the index of the closing ']' together with the translated class.
+func globClass(runes []rune, start int) (int, string, error) {
+	var sb strings.Builder
+	sb.WriteString("[")
+
+	i := start + 1
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		sb.WriteString("^")
+		i++
+	}
+
+	// A ']' directly after the opening bracket is part of the class.
+	first := i
+	for ; i < len(runes); i++ {
+		c := runes[i]
+
+		switch {
+		case c == ']' && i > first:
+			sb.WriteString("]")
+			return i, sb.String(), nil
+		case c == '\\' && i+1 < len(runes):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case c == '\\' || c == '[' || c == ']' || c == '^':
+			sb.WriteString(`\` + string(c))
+		default:
+			sb.WriteRune(c)
+		}
+	}
+
+	return 0, "", fmt.Errorf("unterminated character class")
+}
+
+// checkGlob checks whether a header value matches the configured glob patterns.
+func checkGlob(requestValue string, vHeader *SingleHeader) bool {
+	if vHeader.IsDebug() {
+		fmt.Println("validate-headers (debug): Validating:", requestValue, "with glob:", vHeader.Values)
+	}
+
+	matchCount := 0
+	for _, glob := range vHeader.globs {
+		if glob.MatchString(requestValue) {
+			matchCount++
+		}
+	}
+
+	return checkMatchCount(matchCount, vHeader)
+}
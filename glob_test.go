@@ -0,0 +1,190 @@
+package traefik_plugin_validate_headers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		match   bool
+	}{
+		{pattern: "*.partner.example.com", value: "api.partner.example.com", match: true},
+		{pattern: "*.partner.example.com", value: "a.b.partner.example.com", match: true},
+		{pattern: "*.partner.example.com", value: "partner.example.com", match: false},
+		{pattern: "*.partner.example.com", value: "api.partner.example.com.evil.com", match: false},
+		{pattern: "*.partner.example.com", value: "apixpartnerxexamplexcom", match: false},
+		{pattern: "tenant-*", value: "tenant-42", match: true},
+		{pattern: "tenant-*", value: "my-tenant-42", match: false},
+		{pattern: "v?", value: "v1", match: true},
+		{pattern: "v?", value: "v10", match: false},
+		{pattern: "v[0-9]", value: "v7", match: true},
+		{pattern: "v[0-9]", value: "vx", match: false},
+		{pattern: "v[!0-9]", value: "vx", match: true},
+		{pattern: "v[^0-9]", value: "v7", match: false},
+		{pattern: "[]a]", value: "]", match: true},
+		{pattern: "[a\\]]", value: "]", match: true},
+		{pattern: `a\*b`, value: "a*b", match: true},
+		{pattern: `a\*b`, value: "axxb", match: false},
+		{pattern: "(a|b)+", value: "(a|b)+", match: true},
+		{pattern: "(a|b)+", value: "a", match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.value, func(t *testing.T) {
+			glob, err := compileGlob(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if glob.MatchString(tt.value) != tt.match {
+				t.Errorf("glob %q on %q: got %v, want %v", tt.pattern, tt.value, !tt.match, tt.match)
+			}
+		})
+	}
+}
+
+func TestCompileGlobInvalid(t *testing.T) {
+	for _, pattern := range []string{"[abc", "abc\\", "[]"} {
+		if _, err := compileGlob(pattern); err == nil {
+			t.Errorf("expected an error for glob %q", pattern)
+		}
+	}
+}
+
+func TestGlob(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//MatchOneGlobConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Forwarded-Host",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"*.partner.example.com",
+							"partner.example.com",
+						},
+						Glob:  Bool(true),
+						Debug: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "MatchOneGlob_Success",
+					headers: map[string]string{
+						"X-Forwarded-Host": "api.partner.example.com",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MatchOneGlob_Success_Exact",
+					headers: map[string]string{
+						"X-Forwarded-Host": "partner.example.com",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MatchOneGlob_Fail_Unanchored",
+					headers: map[string]string{
+						"X-Forwarded-Host": "api.partner.example.com.evil.com",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "MatchOneGlob_Fail_InvalidHeader",
+					headers: map[string]string{
+						"InvalidHeader": "invalidValue",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		//MatchAllGlobConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Tenant",
+						MatchType: string(MatchAll),
+						Values: []string{
+							"tenant-*",
+							"*-prod",
+						},
+						Glob: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "MatchAllGlob_Success",
+					headers: map[string]string{
+						"X-Tenant": "tenant-42-prod",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MatchAllGlob_Fail",
+					headers: map[string]string{
+						"X-Tenant": "tenant-42-dev",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		//MatchNoneGlobConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Tenant",
+						MatchType: string(MatchNone),
+						Values: []string{
+							"internal-*",
+						},
+						Glob: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "MatchNoneGlob_Success",
+					headers: map[string]string{
+						"X-Tenant": "tenant-42",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MatchNoneGlob_Fail",
+					headers: map[string]string{
+						"X-Tenant": "internal-ops",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
+
+func TestGlobInvalidConfig(t *testing.T) {
+	_, err := New(nil, http.HandlerFunc(dummyHandler), &Config{
+		Headers: []SingleHeader{
+			{
+				Name:      "X-Tenant",
+				MatchType: string(MatchOne),
+				Values:    []string{"tenant-[a-z"},
+				Glob:      Bool(true),
+			},
+		},
+	}, "test")
+
+	expected := "validate-headers: configuration incorrect:\n  error: headers[0].values[0]: unterminated character class in glob \"tenant-[a-z\""
+	if err == nil || err.Error() != expected {
+		t.Errorf("got %v, want %s", err, expected)
+	}
+}
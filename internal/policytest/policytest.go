@@ -0,0 +1,332 @@
+// Package policytest runs validate-headers configurations against request fixtures without Traefik.
+package policytest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	validateheaders "github.com/frankforpresident/traefik-plugin-validate-headers"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultPlugin is the name under which the plugin is usually declared in the static configuration.
+const DefaultPlugin = "validate-headers"
+
+// reasonHeader is the response header that exposes the failed rule when the configuration doesn't set one.
+const reasonHeader = "X-Validate-Headers-Reason"
+
+// Case is a request fixture and the response it is expected to get.
+type Case struct {
+	Name    string            `yaml:"name"`
+	Method  string            `yaml:"method"`
+	Host    string            `yaml:"host"`
+	Path    string            `yaml:"path"`
+	Headers map[string]Values `yaml:"headers"`
+	Status  int               `yaml:"status"`
+	Reason  string            `yaml:"reason"`
+}
+
+// Values are the values of a request header; a single value can be given as a string.
+type Values []string
+
+// UnmarshalYAML accepts a single value as well as a list of values.
+func (v *Values) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		*v = Values{value}
+		return nil
+	}
+
+	var values []string
+	if err := unmarshal(&values); err != nil {
+		return err
+	}
+
+	*v = values
+
+	return nil
+}
+
+// ExpectedStatus returns the expected status code; 200 when the case doesn't set one.
+func (c *Case) ExpectedStatus() int {
+	if c.Status == 0 {
+		return http.StatusOK
+	}
+
+	return c.Status
+}
+
+// Result is the response to the request of a case.
+type Result struct {
+	Case   Case
+	Status int
+	Reason string
+}
+
+// Passed checks whether the response has the expected status and, when the case sets one, the expected reason.
+func (r *Result) Passed() bool {
+	return r.Status == r.Case.ExpectedStatus() && (r.Case.Reason == "" || r.Reason == r.Case.Reason)
+}
+
+// LoadConfig reads the plugin configuration from a dynamic configuration, selecting the middleware that uses
+// the plugin, from the `testData` of a plugin manifest (`.traefik.yml`) or from a bare plugin configuration.
+// The middleware can be empty when only one middleware uses the plugin. Keys are matched case-insensitively,
+// as Traefik does.
+func LoadConfig(data []byte, plugin, middleware string) (*validateheaders.Config, error) {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	pluginConfig := normalize(document)
+
+	switch {
+	case lookup(pluginConfig, "http") != nil:
+		var err error
+
+		pluginConfig, err = middlewareConfig(pluginConfig, plugin, middleware)
+		if err != nil {
+			return nil, err
+		}
+	case lookup(pluginConfig, "testData") != nil:
+		pluginConfig = lookup(pluginConfig, "testData")
+	}
+
+	return decodeConfig(pluginConfig)
+}
+
+// decodeConfig decodes a plugin configuration, with string keys, into the configuration of the plugin.
+func decodeConfig(pluginConfig interface{}) (*validateheaders.Config, error) {
+	raw, err := json.Marshal(pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	config := validateheaders.CreateConfig()
+	if err := json.Unmarshal(raw, config); err != nil {
+		return nil, fmt.Errorf("invalid plugin configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// middlewareConfig returns the plugin configuration of a middleware in the dynamic configuration.
+func middlewareConfig(document interface{}, plugin, middleware string) (interface{}, error) {
+	middlewares := lookup(lookup(document, "http"), "middlewares")
+
+	configs := make(map[string]interface{})
+
+	if m, ok := middlewares.(map[string]interface{}); ok {
+		for name, definition := range m {
+			if config := lookup(lookup(definition, "plugin"), plugin); config != nil {
+				configs[name] = config
+			}
+		}
+	}
+
+	if middleware != "" {
+		config, ok := configs[middleware]
+		if !ok {
+			return nil, fmt.Errorf("middleware %q doesn't use the plugin %q", middleware, plugin)
+		}
+
+		return config, nil
+	}
+
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	switch len(names) {
+	case 0:
+		return nil, fmt.Errorf("no middleware uses the plugin %q", plugin)
+	case 1:
+		return configs[names[0]], nil
+	default:
+		return nil, fmt.Errorf("more than one middleware uses the plugin %q, select one of: %s", plugin, strings.Join(names, ", "))
+	}
+}
+
+// lookup returns the value of a key in a map, ignoring case; nil when it isn't a map or the key is missing.
+func lookup(value interface{}, key string) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// normalize converts the maps decoded from YAML to maps with string keys, so they can be encoded as JSON.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalize(item)
+		}
+
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = normalize(item)
+		}
+
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = normalize(item)
+		}
+
+		return items
+	default:
+		return value
+	}
+}
+
+// LoadCases reads the request fixtures from the `requests` list of a YAML document.
+func LoadCases(data []byte) ([]Case, error) {
+	var document struct {
+		Requests []Case `yaml:"requests"`
+	}
+
+	if err := yaml.UnmarshalStrict(data, &document); err != nil {
+		return nil, err
+	}
+
+	return document.Requests, nil
+}
+
+// fixture is a YAML file with a plugin configuration and the request fixtures to run against it.
+type fixture struct {
+	Description string      `yaml:"description"`
+	Config      interface{} `yaml:"config"`
+	ConfigFile  string      `yaml:"configfile"`
+	Middleware  string      `yaml:"middleware"`
+	Requests    []Case      `yaml:"requests"`
+}
+
+// LoadFixture reads a fixture file: a bare plugin configuration in `config`, or a file with a configuration,
+// relative to the fixture, in `configfile`, with the request fixtures in `requests`.
+func LoadFixture(path string) (*validateheaders.Config, []Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var f fixture
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, nil, err
+	}
+
+	if (f.Config == nil) == (f.ConfigFile == "") {
+		return nil, nil, fmt.Errorf("expected either config or configfile")
+	}
+
+	if len(f.Requests) == 0 {
+		return nil, nil, fmt.Errorf("missing requests")
+	}
+
+	var config *validateheaders.Config
+
+	if f.ConfigFile != "" {
+		data, err = os.ReadFile(filepath.Join(filepath.Dir(path), f.ConfigFile))
+		if err == nil {
+			config, err = LoadConfig(data, DefaultPlugin, f.Middleware)
+		}
+	} else {
+		config, err = decodeConfig(normalize(f.Config))
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return config, f.Requests, nil
+}
+
+// Run creates the plugin from the configuration, as Traefik does, and sends the request of every case to it.
+// Requests that pass the plugin get a 200 response from the next handler. When the configuration has no reason
+// header one is added, so the results always include the reason of a rejection.
+func Run(config *validateheaders.Config, cases []Case) ([]Result, error) {
+	if config.Error.ReasonHeader == "" {
+		config.Error.ReasonHeader = reasonHeader
+	}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	handler, err := validateheaders.New(context.Background(), next, config, "policytest")
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(cases))
+
+	for _, c := range cases {
+		req, err := newRequest(&c)
+		if err != nil {
+			return nil, fmt.Errorf("request %q: %w", c.Name, err)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		results = append(results, Result{
+			Case:   c,
+			Status: rec.Code,
+			Reason: rec.Header().Get(config.Error.ReasonHeader),
+		})
+	}
+
+	return results, nil
+}
+
+// newRequest builds the request of a case; the method defaults to GET and the path to `/`.
+func newRequest(c *Case) (*http.Request, error) {
+	method := c.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	path := c.Path
+	if path == "" {
+		path = "/"
+	}
+
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Host != "" {
+		req.Host = c.Host
+	}
+
+	for name, values := range c.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	return req, nil
+}
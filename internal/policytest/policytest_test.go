@@ -0,0 +1,340 @@
+package policytest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		yaml          string
+		middleware    string
+		expectedError string
+	}{
+		{
+			name: "BarePluginConfig",
+			yaml: `
+matchtype: one
+headers:
+  - name: X-Api-Key
+    matchtype: one
+    values: [secret]
+`,
+		},
+		{
+			name: "DynamicConfig",
+			yaml: `
+http:
+  middlewares:
+    compress:
+      compress: {}
+    api-key:
+      plugin:
+        validate-headers:
+          matchtype: one
+          headers:
+            - name: X-Api-Key
+              matchtype: one
+              values: [secret]
+`,
+		},
+		{
+			name:       "SelectedMiddleware",
+			middleware: "api-key",
+			yaml: `
+http:
+  middlewares:
+    api-key:
+      plugin:
+        validate-headers:
+          matchType: one
+          Headers:
+            - name: X-Api-Key
+              matchtype: one
+              values: [secret]
+    other:
+      plugin:
+        validate-headers:
+          headers:
+            - name: X-Other
+              matchtype: one
+              values: [other]
+`,
+		},
+		{
+			name: "AmbiguousMiddleware",
+			yaml: `
+http:
+  middlewares:
+    b:
+      plugin:
+        validate-headers: {}
+    a:
+      plugin:
+        validate-headers: {}
+`,
+			expectedError: `more than one middleware uses the plugin "validate-headers", select one of: a, b`,
+		},
+		{
+			name:       "UnknownMiddleware",
+			middleware: "c",
+			yaml: `
+http:
+  middlewares:
+    a:
+      plugin:
+        validate-headers: {}
+`,
+			expectedError: `middleware "c" doesn't use the plugin "validate-headers"`,
+		},
+		{
+			name: "PluginManifest",
+			yaml: `
+displayName: Validate Headers
+type: middleware
+testData:
+  matchtype: one
+  headers:
+    - name: X-Api-Key
+      matchtype: one
+      values: [secret]
+`,
+		},
+		{
+			name:          "InvalidType",
+			yaml:          "headers: yes",
+			expectedError: "invalid plugin configuration: json: cannot unmarshal bool into Go struct field Config.headers of type []traefik_plugin_validate_headers.SingleHeader",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig([]byte(tt.yaml), DefaultPlugin, tt.middleware)
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("got %v, want %s", err, tt.expectedError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if config.MatchType != "one" || len(config.Headers) != 1 || config.Headers[0].Name != "X-Api-Key" {
+				t.Errorf("unexpected configuration: %+v", config)
+			}
+		})
+	}
+}
+
+func TestLoadConfigExamples(t *testing.T) {
+	files := []string{"a-or-b.yaml", "contains-d-and-e.yaml", "multiple-headers.yaml", "none-optional.yaml", "regex.yaml"}
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			data, err := os.ReadFile("../../examples/config/dynamic/" + file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := LoadConfig(data, DefaultPlugin, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Run(config, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLoadCases(t *testing.T) {
+	cases, err := LoadCases([]byte(`
+requests:
+  - name: single
+    method: POST
+    path: /api?tenant=a
+    headers:
+      X-Api-Key: secret
+  - name: multiple
+    headers:
+      X-Forwarded-For: [10.0.0.1, 10.0.0.2]
+    status: 403
+    reason: "X-Api-Key: missing"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Case{
+		{Name: "single", Method: http.MethodPost, Path: "/api?tenant=a", Headers: map[string]Values{"X-Api-Key": {"secret"}}},
+		{Name: "multiple", Headers: map[string]Values{"X-Forwarded-For": {"10.0.0.1", "10.0.0.2"}}, Status: http.StatusForbidden, Reason: "X-Api-Key: missing"},
+	}
+
+	if !reflect.DeepEqual(cases, expected) {
+		t.Errorf("got %+v, want %+v", cases, expected)
+	}
+
+	if _, err := LoadCases([]byte("requests:\n  - name: typo\n    statuscode: 403\n")); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestRun(t *testing.T) {
+	config, err := LoadConfig([]byte(`
+error:
+  statuscode: 404
+headers:
+  - name: X-Api-Key
+    matchtype: one
+    values: [secret]
+  - name: X-Tenant
+    matchtype: one
+    values: ["{query:tenant}"]
+    required: false
+`), DefaultPlugin, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []Case{
+		{Name: "Allowed", Headers: map[string]Values{"X-Api-Key": {"secret"}}},
+		{Name: "Missing", Status: http.StatusNotFound, Reason: "X-Api-Key: missing"},
+		{Name: "Template", Path: "/?tenant=a", Headers: map[string]Values{"X-Api-Key": {"secret"}, "X-Tenant": {"b"}}, Status: http.StatusNotFound, Reason: "X-Tenant: mismatch"},
+		{Name: "UnexpectedStatus", Method: http.MethodPost, Host: "api.example.com"},
+		{Name: "UnexpectedReason", Status: http.StatusNotFound, Reason: "X-Api-Key: mismatch"},
+	}
+
+	results, err := Run(config, cases)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		status int
+		reason string
+		passed bool
+	}{
+		{status: http.StatusOK, passed: true},
+		{status: http.StatusNotFound, reason: "X-Api-Key: missing", passed: true},
+		{status: http.StatusNotFound, reason: "X-Tenant: mismatch", passed: true},
+		{status: http.StatusNotFound, reason: "X-Api-Key: missing", passed: false},
+		{status: http.StatusNotFound, reason: "X-Api-Key: missing", passed: false},
+	}
+
+	for i, result := range results {
+		if result.Status != expected[i].status || result.Reason != expected[i].reason || result.Passed() != expected[i].passed {
+			t.Errorf("%s: got %d %q passed=%v, want %d %q passed=%v", result.Case.Name, result.Status, result.Reason, result.Passed(),
+				expected[i].status, expected[i].reason, expected[i].passed)
+		}
+	}
+}
+
+func TestLoadFixture(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"dynamic.yaml": `
+http:
+  middlewares:
+    api-key:
+      plugin:
+        validate-headers:
+          headers:
+            - name: X-Api-Key
+              matchtype: one
+              values: [secret]
+`,
+		"inline.yaml": `
+description: API key
+config:
+  headers:
+    - name: X-Api-Key
+      matchtype: one
+      values: [secret]
+requests:
+  - name: Allowed
+    headers:
+      X-Api-Key: secret
+`,
+		"configfile.yaml": `
+configfile: dynamic.yaml
+middleware: api-key
+requests:
+  - name: Allowed
+    headers:
+      X-Api-Key: secret
+`,
+		"both.yaml": `
+config: {}
+configfile: dynamic.yaml
+requests:
+  - name: Allowed
+`,
+		"norequests.yaml": `
+config: {}
+`,
+		"unknownfield.yaml": `
+config: {}
+request:
+  - name: Allowed
+`,
+		"unknownmiddleware.yaml": `
+configfile: dynamic.yaml
+middleware: other
+requests:
+  - name: Allowed
+`,
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file          string
+		expectedError string
+	}{
+		{file: "inline.yaml"},
+		{file: "configfile.yaml"},
+		{file: "both.yaml", expectedError: "expected either config or configfile"},
+		{file: "norequests.yaml", expectedError: "missing requests"},
+		{file: "unknownfield.yaml", expectedError: "yaml: unmarshal errors:\n  line 3: field request not found in type policytest.fixture"},
+		{file: "unknownmiddleware.yaml", expectedError: `middleware "other" doesn't use the plugin "validate-headers"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			config, cases, err := LoadFixture(filepath.Join(dir, tt.file))
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("got %v, want %s", err, tt.expectedError)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := Run(config, cases)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(results) != 1 || !results[0].Passed() {
+				t.Errorf("unexpected results: %+v", results)
+			}
+		})
+	}
+}
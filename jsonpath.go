@@ -0,0 +1,146 @@
+package traefik_plugin_validate_headers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// errJSONPathNotFound is returned when the selected key or index doesn't exist in the document.
+var errJSONPathNotFound = errors.New("not found")
+
+// jsonPathSegment is a single step of a JSON path: an object key or an array index.
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// jsonPath is a compiled JSON path such as `$.user.groups[0]`.
+type jsonPath []jsonPathSegment
+
+// compileJSONPath parses a small JSON path syntax: an optional leading '$', followed by
+// '.key', '["key"]' and '[index]' segments. The leading dot of the first key may be omitted.
+func compileJSONPath(expr string) (jsonPath, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	path := jsonPath{}
+
+	for first := true; rest != ""; first = false {
+		switch {
+		case rest[0] == '.' || (first && rest[0] != '['):
+			rest = strings.TrimPrefix(rest, ".")
+
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in JSON path %q", expr)
+			}
+
+			path = append(path, jsonPathSegment{key: rest[:end]})
+			rest = rest[end:]
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket in JSON path %q", expr)
+			}
+
+			inner := rest[1:end]
+			rest = rest[end+1:]
+
+			if strings.HasPrefix(inner, `"`) {
+				key, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid quoted key in JSON path %q", expr)
+				}
+
+				path = append(path, jsonPathSegment{key: key})
+
+				continue
+			}
+
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q in JSON path %q", inner, expr)
+			}
+
+			path = append(path, jsonPathSegment{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("unexpected character %q in JSON path %q", rest[0], expr)
+		}
+	}
+
+	return path, nil
+}
+
+// selectJSON decodes a JSON document and returns the element selected by the path.
+func (p jsonPath) selectJSON(document string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.UseNumber()
+
+	var node interface{}
+	if err := decoder.Decode(&node); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	for _, segment := range p {
+		switch typed := node.(type) {
+		case map[string]interface{}:
+			if segment.isIndex {
+				return nil, fmt.Errorf("cannot index object with [%d]", segment.index)
+			}
+
+			value, ok := typed[segment.key]
+			if !ok {
+				return nil, fmt.Errorf("key %q %w", segment.key, errJSONPathNotFound)
+			}
+
+			node = value
+		case []interface{}:
+			if !segment.isIndex {
+				return nil, fmt.Errorf("cannot select key %q from array", segment.key)
+			}
+
+			if segment.index >= len(typed) {
+				return nil, fmt.Errorf("index %d %w", segment.index, errJSONPathNotFound)
+			}
+
+			node = typed[segment.index]
+		default:
+			return nil, fmt.Errorf("cannot select from scalar value")
+		}
+	}
+
+	return node, nil
+}
+
+// jsonScalarString converts a decoded JSON value into the string that is matched.
+// Strings are returned without quotes, objects and arrays are encoded as compact JSON.
+func jsonScalarString(node interface{}) (string, error) {
+	switch typed := node.(type) {
+	case string:
+		return typed, nil
+	case json.Number:
+		return typed.String(), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case nil:
+		return "null", nil
+	default:
+		var buf bytes.Buffer
+
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+
+		if err := encoder.Encode(typed); err != nil {
+			return "", err
+		}
+
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	}
+}
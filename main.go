@@ -5,41 +5,92 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"net/url"
 	"regexp"
 	"strings"
 )
 
 // SingleHeader contains a single header key pair.
 type SingleHeader struct {
-	Name      string   `json:"name,omitempty"`
-	Values    []string `json:"values,omitempty"`
-	MatchType string   `json:"matchtype"`
-	Required  *bool    `json:"required,omitempty"`
-	Contains  *bool    `json:"contains,omitempty"`
-	URLDecode *bool    `json:"urldecode,omitempty"`
-	Debug     *bool    `json:"debug,omitempty"`
-	Regex     *bool    `json:"regex,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Values        []string `json:"values,omitempty"`
+	MatchType     string   `json:"matchtype"`
+	Count         int      `json:"count,omitempty"`
+	Weight        int      `json:"weight,omitempty"`
+	Required      *bool    `json:"required,omitempty"`
+	Contains      *bool    `json:"contains,omitempty"`
+	URLDecode     *bool    `json:"urldecode,omitempty"`
+	URLDecodeMode string   `json:"urldecodemode,omitempty"`
+	Debug         *bool    `json:"debug,omitempty"`
+	Regex         *bool    `json:"regex,omitempty"`
+	Glob          *bool    `json:"glob,omitempty"`
+	Prefix        *bool    `json:"prefix,omitempty"`
+	Suffix        *bool    `json:"suffix,omitempty"`
+	MediaType     *bool    `json:"mediatype,omitempty"`
+	Language      *bool    `json:"language,omitempty"`
+	MinLength     int      `json:"minlength,omitempty"`
+	MaxLength     int      `json:"maxlength,omitempty"`
+	Charset       string   `json:"charset,omitempty"`
+	Format        string   `json:"format,omitempty"`
+	Transforms    []string `json:"transforms,omitempty"`
+	Source        string   `json:"source,omitempty"`
+	Path          string   `json:"path,omitempty"`
+	Preference    string   `json:"preference,omitempty"`
+	Absent        *bool    `json:"absent,omitempty"`
+	Names         []string `json:"names,omitempty"`
+	Strip         *bool    `json:"strip,omitempty"`
+	NamePattern   string   `json:"namepattern,omitempty"`
+	NameRegex     *bool    `json:"nameregex,omitempty"`
+	MinCount      int      `json:"mincount,omitempty"`
+
+	globs       []*regexp.Regexp
+	regexes     []*regexp.Regexp
+	namePattern *regexp.Regexp
+	// valueSet counts the configured values of exact matches, and contains finds the configured values of
+	// contains matches, for long lists of values without templates.
+	valueSet map[string]int
+	contains *ahoCorasick
+	// canonicalName is the canonical form of the name, so exact lookups don't canonicalize it per request.
+	canonicalName string
+	// nameResolution is the name resolution policy of the plugin configuration.
+	nameResolution string
+	mediaRanges    []mediaRange
+	templates      []valueTemplate
+	transforms     []transform
+	jsonPath       jsonPath
+	sfSelector     *sfSelector
+	// sfValues are the canonical serializations of the values of exactly matched structured field headers.
+	sfValues []string
 }
 
 // Config represents the plugin configuration.
 type Config struct {
-	Headers   []SingleHeader
-	MatchType string `json:"matchtype,omitempty"`
-	Error     ErrorConfig
+	Headers        []SingleHeader
+	MatchType      string `json:"matchtype,omitempty"`
+	Count          int    `json:"count,omitempty"`
+	Error          ErrorConfig
+	Digest         DigestConfig      `json:"digest,omitempty"`
+	MaxHeaderCount int               `json:"maxheadercount,omitempty"`
+	MaxHeaderBytes int               `json:"maxheaderbytes,omitempty"`
+	Rules          []ConditionalRule `json:"rules,omitempty"`
+	NameResolution string            `json:"nameresolution,omitempty"`
+	Threshold      int               `json:"threshold,omitempty"`
+	ScoreHeader    string            `json:"scoreheader,omitempty"`
 }
 
 type ErrorConfig struct {
-	StatusCode int    `json:"statuscode,omitempty"`
-	Message    string `json:"message,omitempty"`
+	StatusCode   int    `json:"statuscode,omitempty"`
+	Message      string `json:"message,omitempty"`
+	ReasonHeader string `json:"reasonheader,omitempty"`
 }
 
 // Validator is the main handler for the Validator plugin.
 type Validator struct {
-	next    http.Handler
-	headers []SingleHeader
-	config  *Config
-	name    string
+	next     http.Handler
+	headers  []SingleHeader
+	absent   []SingleHeader
+	families []SingleHeader
+	config   *Config
+	name     string
 }
 
 // MatchType is an enum specifying the match type for the 'contains' config.
@@ -52,8 +103,49 @@ const (
 	MatchOne MatchType = "one"
 	// MatchNone requires none of the values to be matched.
 	MatchNone MatchType = "none"
+	// MatchAtLeast requires at least 'count' values to be matched.
+	MatchAtLeast MatchType = "atleast"
+	// MatchAtMost requires at most 'count' values to be matched.
+	MatchAtMost MatchType = "atmost"
+	// MatchExactly requires exactly 'count' values to be matched.
+	MatchExactly MatchType = "exactly"
+	// MatchScore adds up the weights of the failing headers and rejects requests above the threshold.
+	MatchScore MatchType = "score"
 )
 
+// headerMatchTypes are the match types that can be used per header.
+var headerMatchTypes = []MatchType{MatchAll, MatchOne, MatchNone, MatchAtLeast, MatchAtMost, MatchExactly}
+
+// configMatchTypes are the match types that can be used for the plugin.
+var configMatchTypes = []MatchType{MatchAll, MatchOne, MatchNone, MatchAtLeast, MatchAtMost, MatchExactly, MatchScore}
+
+// matchTypeAliases maps alternative names to match types.
+var matchTypeAliases = map[string]MatchType{
+	"any": MatchOne,
+}
+
+// ParseMatchType parses a match type, ignoring case and surrounding spaces and resolving aliases such as 'any'.
+// It returns an error listing the allowed values when the match type is unknown.
+func ParseMatchType(value string, allowed []MatchType) (MatchType, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+
+	if alias, ok := matchTypeAliases[normalized]; ok {
+		normalized = string(alias)
+	}
+
+	names := make([]string, 0, len(allowed))
+
+	for _, matchType := range allowed {
+		if normalized == string(matchType) {
+			return matchType, nil
+		}
+
+		names = append(names, string(matchType))
+	}
+
+	return "", fmt.Errorf("unknown matchtype %q, allowed: %s", value, strings.Join(names, ", "))
+}
+
 // CreateConfig creates the default plugin configuration.
 func CreateConfig() *Config {
 	return &Config{
@@ -66,10 +158,11 @@ func CreateConfig() *Config {
 	}
 }
 
-// New creates a new Validator plugin.
+// New creates a new Validator plugin. A configuration with errors is rejected with every problem found by
+// ValidateConfig.
 func New(ctx context.Context, next http.Handler, config *Config, name string) (http.Handler, error) {
-	if len(config.Headers) == 0 {
-		return nil, fmt.Errorf("validate-headers: configuration incorrect, missing headers")
+	if err := ValidateConfig(config).Err(); err != nil {
+		return nil, err
 	}
 
 	// Set default values for custom error response.
@@ -81,147 +174,534 @@ func New(ctx context.Context, next http.Handler, config *Config, name string) (h
 		config.Error.Message = "Not allowed"
 	}
 
-	for _, vHeader := range config.Headers {
+	if config.Digest.MaxBodySize == 0 {
+		config.Digest.MaxBodySize = defaultDigestMaxBodySize
+	}
+
+	if strings.TrimSpace(config.MatchType) == "" {
+		config.MatchType = string(MatchAll)
+	}
+
+	matchType, err := ParseMatchType(config.MatchType, configMatchTypes)
+	if err != nil {
+		return nil, fmt.Errorf("validate-headers: configuration incorrect, %w", err)
+	}
+
+	config.MatchType = string(matchType)
+
+	var headers, absent, families []SingleHeader
+
+	for i := range config.Headers {
+		if err := compileHeader(fmt.Sprintf("headers[%d]", i), &config.Headers[i], config.NameResolution).Err(); err != nil {
+			return nil, err
+		}
+
+		// Absent headers and header families are checked before, and independent of, the match type,
+		// unless they contribute to the score.
+		switch {
+		case config.MatchType == string(MatchScore):
+			headers = append(headers, config.Headers[i])
+		case config.Headers[i].IsAbsent():
+			absent = append(absent, config.Headers[i])
+		case config.Headers[i].namePattern != nil:
+			families = append(families, config.Headers[i])
+		default:
+			headers = append(headers, config.Headers[i])
+		}
+	}
+
+	if err := compileRules(config.Rules, config.NameResolution); err != nil {
+		return nil, err
+	}
+
+	return &Validator{
+		headers:  headers,
+		absent:   absent,
+		families: families,
+		config:   config, // Store the config for later use.
+		next:     next,
+		name:     name,
+	}, nil
+}
+
+// compileHeader validates the configuration of a header and compiles its patterns. Every problem is returned
+// with the path of its setting below path, e.g. `headers[3].values[1]`; the patterns are only compiled when
+// there are none.
+func compileHeader(path string, vHeader *SingleHeader, nameResolution string) Diagnostics {
+	var diagnostics Diagnostics
+
+	vHeader.nameResolution = nameResolution
+
+	if err := compileNamePattern(vHeader); err != nil {
+		diagnostics.add(path+".namepattern", err)
+	}
 
-		if strings.TrimSpace(vHeader.Name) == "" {
-			return nil, fmt.Errorf("validate-headers: configuration incorrect, missing header name")
+	// The match type is checked before the absent branch, so a typo is never silently ignored.
+	if strings.TrimSpace(vHeader.MatchType) != "" {
+		matchType, err := ParseMatchType(vHeader.MatchType, headerMatchTypes)
+		if err != nil {
+			diagnostics.add(path+".matchtype", err)
+		} else {
+			vHeader.MatchType = string(matchType)
 		}
+	}
 
-		if vHeader.MatchType == string(MatchAll) && !(vHeader.IsContains() || vHeader.IsRegex()) {
-			return nil, fmt.Errorf("validate-headers: configuration incorrect for header %v, %s", vHeader.Name, "match-all can only be used in combination with 'contains' or 'regex'")
+	if vHeader.IsAbsent() {
+		if err := compileAbsent(vHeader); err != nil {
+			diagnostics.add(path, err)
 		}
 
-		if strings.TrimSpace(vHeader.MatchType) == "" {
-			return nil, fmt.Errorf("validate-headers: configuration incorrect, missing match type configuration for header %v", vHeader.Name)
+		return diagnostics
+	}
+
+	if vHeader.namePattern == nil && isExactNameResolution(nameResolution) {
+		vHeader.canonicalName = http.CanonicalHeaderKey(vHeader.Name)
+	}
+
+	if len(vHeader.Names) > 0 || vHeader.IsStrip() {
+		diagnostics.errorf(path, "names and strip can only be used in combination with absent")
+	}
+
+	if strings.TrimSpace(vHeader.displayName()) == "" && vHeader.NamePattern == "" {
+		diagnostics.errorf(path+".name", "missing header name")
+	}
+
+	matchModes := vHeader.matchModes()
+
+	if len(matchModes) > 1 {
+		diagnostics.errorf(path, "only one of 'contains', 'regex', 'glob', 'prefix', 'suffix', 'mediatype' or 'language' can be used, found %s", strings.Join(matchModes, ", "))
+	}
+
+	if vHeader.MatchType == string(MatchAll) && len(matchModes) == 0 {
+		diagnostics.errorf(path+".matchtype", "match-all can only be used in combination with 'contains', 'regex', 'glob', 'prefix', 'suffix', 'mediatype' or 'language'")
+	}
+
+	diagnostics = append(diagnostics, validateConstraints(path, vHeader)...)
+
+	if err := validateURLDecodeMode(vHeader.URLDecodeMode); err != nil {
+		diagnostics.add(path+".urldecodemode", err)
+	} else if vHeader.URLDecodeMode == urlDecodeLenientAlias {
+		vHeader.URLDecodeMode = URLDecodeStrict
+	}
+
+	transforms, err := compileTransforms(vHeader.Transforms)
+	if err != nil {
+		diagnostics.add(path+".transforms", err)
+	}
+
+	vHeader.transforms = transforms
+
+	if err := compileSource(vHeader); err != nil {
+		diagnostics.add(path+".source", err)
+	}
+
+	if !isCountMatchType(vHeader.MatchType) && vHeader.Count != 0 {
+		diagnostics.add(path+".count", validateCount(vHeader.MatchType, vHeader.Count, 0))
+	}
+
+	// Headers with only constraints, or header families with only a minimum count, don't need values or a match type.
+	if len(vHeader.Values) == 0 && len(matchModes) == 0 && (vHeader.hasConstraints() || vHeader.MinCount > 0) {
+		return diagnostics
+	}
+
+	if strings.TrimSpace(vHeader.MatchType) == "" {
+		diagnostics.errorf(path+".matchtype", "missing match type configuration")
+	}
+
+	if len(vHeader.Values) == 0 {
+		diagnostics.errorf(path+".values", "missing header values")
+
+		return diagnostics
+	}
+
+	for j, value := range vHeader.Values {
+		if err := validateValue(vHeader, value); err != nil {
+			diagnostics.add(fmt.Sprintf("%s.values[%d]", path, j), err)
 		}
+	}
+
+	// Without a match mode a value can only equal one of the configured values.
+	possible := len(vHeader.Values)
+	if len(matchModes) == 0 && possible > 1 {
+		possible = 1
+	}
 
-		if len(vHeader.Values) == 0 {
-			return nil, fmt.Errorf("validate-headers: configuration incorrect, missing header values")
+	if isCountMatchType(vHeader.MatchType) {
+		if err := validateCount(vHeader.MatchType, vHeader.Count, possible); err != nil {
+			diagnostics.add(path+".count", err)
 		}
+	}
+
+	vHeader.templates = compileTemplates(vHeader.Values)
+
+	if vHeader.templates != nil && (vHeader.IsGlob() || vHeader.IsMediaType()) {
+		diagnostics.errorf(path+".values", "templates can't be used in combination with 'glob' or 'mediatype'")
+	}
+
+	if len(diagnostics) > 0 {
+		return diagnostics
+	}
+
+	if vHeader.IsGlob() {
+		vHeader.globs = make([]*regexp.Regexp, 0, len(vHeader.Values))
 
 		for _, value := range vHeader.Values {
-			if strings.TrimSpace(value) == "" {
-				return nil, fmt.Errorf("validate-headers: configuration incorrect, empty value found")
+			glob, err := compileGlob(value)
+			if err != nil {
+				diagnostics.add(path+".values", err)
+				return diagnostics
 			}
+
+			vHeader.globs = append(vHeader.globs, glob)
 		}
 	}
 
-	return &Validator{
-		headers: config.Headers,
-		config:  config, // Store the config for later use.
-		next:    next,
-		name:    name,
-	}, nil
+	if vHeader.IsRegex() {
+		vHeader.regexes = compileRegexes(vHeader.Values)
+	}
+
+	if vHeader.templates == nil && len(vHeader.Values) >= minCompiledValues {
+		if len(matchModes) == 0 {
+			vHeader.valueSet = compileValueSet(vHeader.exactValues())
+		} else if vHeader.IsContains() {
+			vHeader.contains = compileAhoCorasick(vHeader.Values)
+		}
+	}
+
+	if vHeader.IsMediaType() {
+		mediaRanges, err := compileMediaRanges(vHeader.Values)
+		if err != nil {
+			diagnostics.add(path+".values", err)
+			return diagnostics
+		}
+
+		vHeader.mediaRanges = mediaRanges
+	}
+
+	return diagnostics
 }
 
 // ServeHTTP handles the HTTP request and validates headers based on the configured match type.
 func (a *Validator) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	headersValid := true
+	failure := checkLimits(a.config, req)
 
-	switch a.config.MatchType {
-	case string(MatchNone):
-		headersValid = checkNone(a.headers, req)
-	case string(MatchAll):
-		headersValid = checkAll(a.headers, req)
-	case string(MatchOne):
-		headersValid = checkOne(a.headers, req)
-	default:
-		// Unsupported MatchType, treat as MatchAll for backward compatibility.
-		headersValid = checkAll(a.headers, req)
+	for i := 0; failure == nil && i < len(a.absent); i++ {
+		failure = checkAbsent(&a.absent[i], req)
 	}
 
-	if headersValid {
-		a.next.ServeHTTP(rw, req)
-	} else {
-		http.Error(rw, a.config.Error.Message, a.config.Error.StatusCode)
+	for i := 0; failure == nil && i < len(a.families); i++ {
+		failure = checkFamily(&a.families[i], req)
 	}
-}
-
-// checkNone checks whether none of the configured headers are present in the request.
-func checkNone(headers []SingleHeader, req *http.Request) bool {
-	isValid := true
 
-	for _, vHeader := range headers {
-		if !isValid {
-			return false
+	if failure == nil {
+		switch {
+		case len(a.headers) == 0:
+			// Only absent headers, header families, the rules or the digest are verified.
+		case a.config.MatchType == string(MatchNone):
+			failure = checkNone(a.headers, req)
+		case a.config.MatchType == string(MatchOne):
+			failure = checkOne(a.headers, req)
+		case isCountMatchType(a.config.MatchType):
+			failure = checkCount(a.headers, a.config.MatchType, a.config.Count, req)
+		case a.config.MatchType == string(MatchScore):
+			failure = checkScore(a.headers, a.config, req)
+		default:
+			// MatchAll; unknown match types are rejected in New.
+			failure = checkAll(a.headers, req)
 		}
+	}
+
+	if failure == nil {
+		failure = checkRules(a.config.Rules, req)
+	}
+
+	if failure == nil && a.config.Digest.IsEnabled() {
+		failure = checkDigest(&a.config.Digest, req)
+	}
 
-		reqHeaderVal := req.Header.Get(vHeader.Name)
+	if failure == nil {
+		a.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if a.config.Error.ReasonHeader != "" {
+		rw.Header().Set(a.config.Error.ReasonHeader, failure.summary())
+	}
 
-		if vHeader.IsURLDecode() {
-			reqHeaderVal, _ = url.QueryUnescape(reqHeaderVal)
+	http.Error(rw, a.config.Error.Message, a.config.Error.StatusCode)
+}
+
+// checkNone checks whether none of the configured headers are present in the request.
+func checkNone(headers []SingleHeader, req *http.Request) *Failure {
+	var buf [1]string
+
+	for i := range headers {
+		vHeader := &headers[i]
+		reqHeaderVals, failure := requestValues(req, vHeader, buf[:0])
+		if failure != nil {
+			return failure
 		}
 
-		if reqHeaderVal != "" {
-			if !checkRequired(&reqHeaderVal, &vHeader) {
-				isValid = false
+		if len(reqHeaderVals) > 0 {
+			matched, failure := matchValues(req, reqHeaderVals, vHeader, checkRequired)
+			if failure != nil {
+				return failure
 			}
-		} else {
-			if !checkRequired(&reqHeaderVal, &vHeader) {
-				isValid = true
+
+			if !matched {
+				return &Failure{Header: vHeader.Name, Reason: ReasonMismatch}
 			}
 		}
 	}
 
-	return isValid
+	return nil
 }
 
 // checkAll checks whether all of the configured headers match in the request.
-func checkAll(headers []SingleHeader, req *http.Request) bool {
-	isValid := true
-
-	for _, vHeader := range headers {
-		if !isValid {
-			return false
+func checkAll(headers []SingleHeader, req *http.Request) *Failure {
+	for i := range headers {
+		if failure := checkHeader(&headers[i], req); failure != nil {
+			return failure
 		}
+	}
+
+	return nil
+}
+
+// checkHeader checks a single configured header: it must be absent, all headers of its family must pass,
+// or the header value must match, and a missing header fails when it is required.
+func checkHeader(vHeader *SingleHeader, req *http.Request) *Failure {
+	if vHeader.IsAbsent() {
+		return checkAbsent(vHeader, req)
+	}
+
+	if vHeader.namePattern != nil {
+		return checkFamily(vHeader, req)
+	}
+
+	var buf [1]string
 
-		reqHeaderVal := req.Header.Get(vHeader.Name)
+	reqHeaderVals, failure := requestValues(req, vHeader, buf[:0])
+	if failure != nil {
+		return failure
+	}
 
-		if vHeader.IsURLDecode() {
-			reqHeaderVal, _ = url.QueryUnescape(reqHeaderVal)
+	if len(reqHeaderVals) > 0 {
+		matched, failure := matchValues(req, reqHeaderVals, vHeader, checkMatches)
+		if failure != nil {
+			return failure
 		}
 
-		if reqHeaderVal != "" {
-			if !checkMatches(&reqHeaderVal, &vHeader) {
-				isValid = false
-			}
-		} else {
-			if !checkRequired(&reqHeaderVal, &vHeader) {
-				isValid = false
-			}
+		if !matched {
+			return &Failure{Header: vHeader.Name, Reason: ReasonMismatch}
 		}
+	} else if vHeader.IsRequired() {
+		return &Failure{Header: vHeader.Name, Reason: ReasonMissing}
 	}
 
-	return isValid
+	return nil
 }
 
 // checkOne checks whether at least one of the configured headers matches in the request.
-func checkOne(headers []SingleHeader, req *http.Request) bool {
+// Malformed values and constraint violations reject the request even when another header matches.
+func checkOne(headers []SingleHeader, req *http.Request) *Failure {
 	isValid := false
 
-	for _, vHeader := range headers {
-		reqHeaderVal := req.Header.Get(vHeader.Name)
+	var failure *Failure
+
+	var buf [1]string
 
-		if vHeader.IsURLDecode() {
-			reqHeaderVal, _ = url.QueryUnescape(reqHeaderVal)
+	for i := range headers {
+		vHeader := &headers[i]
+		reqHeaderVals, valueFailure := requestValues(req, vHeader, buf[:0])
+		if valueFailure != nil {
+			return valueFailure
 		}
 
-		if reqHeaderVal != "" {
-			if checkMatches(&reqHeaderVal, &vHeader) {
+		if len(reqHeaderVals) > 0 {
+			matched, matchFailure := matchValues(req, reqHeaderVals, vHeader, checkMatches)
+			if matchFailure != nil {
+				return matchFailure
+			}
+
+			if matched {
 				isValid = true
+			} else if failure == nil {
+				failure = &Failure{Header: vHeader.Name, Reason: ReasonMismatch}
 			}
-		} else {
-			if !checkRequired(&reqHeaderVal, &vHeader) {
-				isValid = false
+		} else if vHeader.IsRequired() {
+			isValid = false
+			failure = &Failure{Header: vHeader.Name, Reason: ReasonMissing}
+		}
+	}
+
+	if isValid {
+		return nil
+	}
+
+	if failure == nil {
+		failure = &Failure{Reason: ReasonMissing, Detail: "none of the configured headers is present"}
+	}
+
+	return failure
+}
+
+// matchValues checks the format of every value, resolves the templated configured values and applies the matcher.
+// With match type 'none' every value has to pass the matcher, otherwise one passing value is enough.
+func matchValues(req *http.Request, reqHeaderVals []string, vHeader *SingleHeader, matcher func(string, *SingleHeader) bool) (bool, *Failure) {
+	for _, reqHeaderVal := range reqHeaderVals {
+		if failure := checkFormat(reqHeaderVal, vHeader); failure != nil {
+			return false, failure
+		}
+	}
+
+	vHeader, failure := resolveValues(req, vHeader)
+	if failure != nil {
+		return false, failure
+	}
+
+	if vHeader.MatchType == string(MatchNone) {
+		for i := range reqHeaderVals {
+			if !matcher(reqHeaderVals[i], vHeader) {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}
+
+	for i := range reqHeaderVals {
+		if matcher(reqHeaderVals[i], vHeader) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// requestValues returns the values of the header in the request that are matched; empty when the header is missing.
+// Without a source this is the single request value, appended to buf so the caller can keep it on the stack,
+// otherwise the values extracted from it.
+func requestValues(req *http.Request, vHeader *SingleHeader, buf []string) ([]string, *Failure) {
+	reqHeaderVal, failure := requestValue(req, vHeader)
+	if failure != nil || reqHeaderVal == "" {
+		return nil, failure
+	}
+
+	reqHeaderVals := append(buf, reqHeaderVal)
+
+	if vHeader.Source != "" {
+		var err error
+
+		reqHeaderVals, err = extractValues(reqHeaderVal, vHeader)
+		if err != nil {
+			return nil, malformed(vHeader, err)
+		}
+	}
+
+	if vHeader.IsMediaType() {
+		for _, value := range reqHeaderVals {
+			if _, err := parseRequestMediaTypes(value, vHeader); err != nil {
+				return nil, malformed(vHeader, err)
 			}
 		}
 	}
 
-	return isValid
+	return reqHeaderVals, nil
+}
+
+// requestValue returns the value of the header in the request after decoding and applying the configured transforms.
+// The length and charset are checked on the value as it was sent; decoding and transform errors result in a
+// malformed failure.
+func requestValue(req *http.Request, vHeader *SingleHeader) (string, *Failure) {
+	reqHeaderVal, failure := headerValue(req, vHeader.lookupName(), vHeader.nameResolution)
+	if failure != nil || reqHeaderVal == "" {
+		return "", failure
+	}
+
+	if failure := checkLengthAndCharset(reqHeaderVal, vHeader); failure != nil {
+		return "", failure
+	}
+
+	if vHeader.IsURLDecode() {
+		decoded, err := urlDecode(reqHeaderVal, vHeader.URLDecodeMode)
+		if err != nil {
+			return "", malformed(vHeader, err)
+		}
+
+		reqHeaderVal = decoded
+	}
+
+	if reqHeaderVal == "" || len(vHeader.transforms) == 0 {
+		return reqHeaderVal, nil
+	}
+
+	transformed, err := applyTransforms(reqHeaderVal, vHeader.transforms)
+	if err != nil {
+		return "", malformed(vHeader, err)
+	}
+
+	return transformed, nil
+}
+
+// exactValues returns the values that request values are compared with exactly.
+func (s *SingleHeader) exactValues() []string {
+	if s.sfValues != nil {
+		return s.sfValues
+	}
+
+	return s.Values
+}
+
+// lookupName returns the name to look the header up in the request.
+func (s *SingleHeader) lookupName() string {
+	if s.canonicalName != "" {
+		return s.canonicalName
+	}
+
+	return s.Name
+}
+
+// minCompiledValues is the number of values from which exact and contains matches use a value set or an automaton;
+// shorter lists are scanned, which is at least as fast.
+const minCompiledValues = 8
+
+// compileValueSet counts the configured values, so an exact match counts duplicates as a scan would.
+func compileValueSet(values []string) map[string]int {
+	valueSet := make(map[string]int, len(values))
+	for _, value := range values {
+		valueSet[value]++
+	}
+
+	return valueSet
+}
+
+// compileRegexes compiles the regex values; invalid values get no regexp and are reported when matching.
+func compileRegexes(values []string) []*regexp.Regexp {
+	regexes := make([]*regexp.Regexp, len(values))
+
+	for i, value := range values {
+		regexes[i], _ = regexp.Compile(value)
+	}
+
+	return regexes
+}
+
+// malformed creates a malformed failure for the header and prints it when debugging is enabled.
+func malformed(vHeader *SingleHeader, err error) *Failure {
+	if vHeader.IsDebug() {
+		fmt.Println("validate-headers (debug): ERROR processing value:", err)
+	}
+
+	return &Failure{Header: vHeader.Name, Reason: ReasonMalformed, Detail: err.Error()}
 }
 
 // checkMatches checks whether the header matches the configuration.
-func checkMatches(requestValue *string, vHeader *SingleHeader) bool {
+func checkMatches(requestValue string, vHeader *SingleHeader) bool {
+	if len(vHeader.Values) == 0 {
+		return true
+	}
+
 	if vHeader.IsContains() {
 		return checkContains(requestValue, vHeader)
 	}
@@ -230,42 +710,99 @@ func checkMatches(requestValue *string, vHeader *SingleHeader) bool {
 		return checkRegex(requestValue, vHeader)
 	}
 
+	if vHeader.IsGlob() {
+		return checkGlob(requestValue, vHeader)
+	}
+
+	if vHeader.IsPrefix() {
+		return checkPrefix(requestValue, vHeader)
+	}
+
+	if vHeader.IsSuffix() {
+		return checkSuffix(requestValue, vHeader)
+	}
+
+	if vHeader.IsMediaType() {
+		return checkMediaType(requestValue, vHeader)
+	}
+
+	if vHeader.IsLanguage() {
+		return checkLanguage(requestValue, vHeader)
+	}
+
 	return checkRequired(requestValue, vHeader)
 }
 
 // checkContains checks whether a header value contains the configured value.
-func checkContains(requestValue *string, vHeader *SingleHeader) bool {
+func checkContains(requestValue string, vHeader *SingleHeader) bool {
 	if vHeader.IsDebug() {
-		fmt.Println("validate-headers (debug): Validating contains:", *requestValue, vHeader.Values)
+		fmt.Println("validate-headers (debug): Validating contains:", requestValue, vHeader.Values)
+	}
+
+	if vHeader.contains != nil {
+		first := vHeader.MatchType != string(MatchAll) && !isCountMatchType(vHeader.MatchType)
+		return checkMatchCount(vHeader.contains.matchCount(requestValue, first), vHeader)
 	}
 
 	matchCount := 0
 	for _, value := range vHeader.Values {
-		if strings.Contains(*requestValue, value) {
+		if strings.Contains(requestValue, value) {
 			matchCount++
 		}
 	}
 
-	if vHeader.MatchType == string(MatchNone) {
-		return matchCount == 0
+	return checkMatchCount(matchCount, vHeader)
+}
+
+// checkPrefix checks whether a header value starts with the configured value.
+func checkPrefix(requestValue string, vHeader *SingleHeader) bool {
+	if vHeader.IsDebug() {
+		fmt.Println("validate-headers (debug): Validating prefix:", requestValue, vHeader.Values)
 	}
 
-	if matchCount == 0 || (vHeader.MatchType == string(MatchAll) && matchCount != len(vHeader.Values)) {
-		return false
+	matchCount := 0
+	for _, value := range vHeader.Values {
+		if strings.HasPrefix(requestValue, value) {
+			matchCount++
+		}
 	}
 
-	return true
+	return checkMatchCount(matchCount, vHeader)
 }
 
-// checkRegex checks whether a header value matches the configured regex.
-func checkRegex(requestValue *string, vHeader *SingleHeader) bool {
+// checkSuffix checks whether a header value ends with the configured value.
+func checkSuffix(requestValue string, vHeader *SingleHeader) bool {
 	if vHeader.IsDebug() {
-		fmt.Println("validate-headers (debug): Validating:", *requestValue, "with regex:", vHeader.Values)
+		fmt.Println("validate-headers (debug): Validating suffix:", requestValue, vHeader.Values)
 	}
 
 	matchCount := 0
 	for _, value := range vHeader.Values {
-		match, err := regexp.MatchString(value, *requestValue)
+		if strings.HasSuffix(requestValue, value) {
+			matchCount++
+		}
+	}
+
+	return checkMatchCount(matchCount, vHeader)
+}
+
+// checkRegex checks whether a header value matches the configured regex.
+func checkRegex(requestValue string, vHeader *SingleHeader) bool {
+	if vHeader.IsDebug() {
+		fmt.Println("validate-headers (debug): Validating:", requestValue, "with regex:", vHeader.Values)
+	}
+
+	matchCount := 0
+	for i, value := range vHeader.Values {
+		if i < len(vHeader.regexes) && vHeader.regexes[i] != nil {
+			if vHeader.regexes[i].MatchString(requestValue) {
+				matchCount++
+			}
+
+			continue
+		}
+
+		match, err := regexp.MatchString(value, requestValue)
 
 		if err == nil && match {
 			matchCount++
@@ -274,10 +811,19 @@ func checkRegex(requestValue *string, vHeader *SingleHeader) bool {
 		}
 	}
 
+	return checkMatchCount(matchCount, vHeader)
+}
+
+// checkMatchCount applies the header match type to the number of configured values that matched.
+func checkMatchCount(matchCount int, vHeader *SingleHeader) bool {
 	if vHeader.MatchType == string(MatchNone) {
 		return matchCount == 0
 	}
 
+	if isCountMatchType(vHeader.MatchType) {
+		return compareCount(vHeader.MatchType, matchCount, vHeader.Count)
+	}
+
 	if matchCount == 0 || (vHeader.MatchType == string(MatchAll) && matchCount != len(vHeader.Values)) {
 		return false
 	}
@@ -286,36 +832,42 @@ func checkRegex(requestValue *string, vHeader *SingleHeader) bool {
 }
 
 // checkRequired checks whether a header value is required in the request.
-func checkRequired(requestValue *string, vHeader *SingleHeader) bool {
+func checkRequired(requestValue string, vHeader *SingleHeader) bool {
 	if vHeader.IsDebug() {
-		fmt.Println("validate-headers (debug): Validating required:", *requestValue, vHeader.Values)
+		fmt.Println("validate-headers (debug): Validating required:", requestValue, vHeader.Values)
 	}
 
-	matchCount := 0
-	for _, value := range vHeader.Values {
-		if *requestValue == value {
-			matchCount++
-		}
+	if requestValue == "" {
+		return !vHeader.IsRequired()
+	}
 
-		if !vHeader.IsRequired() && *requestValue == "" {
-			matchCount++
-		}
+	if len(vHeader.Values) == 0 {
+		return true
 	}
 
-	if vHeader.MatchType == string(MatchNone) {
-		if *requestValue == "" {
-			return !vHeader.IsRequired()
+	matchCount := vHeader.valueSet[requestValue]
+	if vHeader.valueSet == nil {
+		for _, value := range vHeader.exactValues() {
+			if requestValue == value {
+				matchCount++
+			}
 		}
+	}
 
+	if vHeader.MatchType == string(MatchNone) {
 		return matchCount == 0
 	}
 
+	if isCountMatchType(vHeader.MatchType) {
+		return compareCount(vHeader.MatchType, matchCount, vHeader.Count)
+	}
+
 	return matchCount > 0
 }
 
-// IsURLDecode checks whether a header value should be URL decoded before testing it.
+// IsURLDecode checks whether a header value should be URL decoded before testing it; setting a URL decode mode implies decoding.
 func (s *SingleHeader) IsURLDecode() bool {
-	return s.URLDecode != nil && *s.URLDecode
+	return (s.URLDecode != nil && *s.URLDecode) || s.URLDecodeMode != ""
 }
 
 // IsDebug checks whether a header value should print debug information in the log.
@@ -337,3 +889,78 @@ func (s *SingleHeader) IsRequired() bool {
 func (s *SingleHeader) IsRegex() bool {
 	return s.Regex != nil && *s.Regex
 }
+
+// IsGlob checks whether a header value should be matched using glob patterns.
+func (s *SingleHeader) IsGlob() bool {
+	return s.Glob != nil && *s.Glob
+}
+
+// IsPrefix checks whether a header value should start with the configured value.
+func (s *SingleHeader) IsPrefix() bool {
+	return s.Prefix != nil && *s.Prefix
+}
+
+// IsSuffix checks whether a header value should end with the configured value.
+func (s *SingleHeader) IsSuffix() bool {
+	return s.Suffix != nil && *s.Suffix
+}
+
+// IsMediaType checks whether a header value should be compared as a media type.
+func (s *SingleHeader) IsMediaType() bool {
+	return s.MediaType != nil && *s.MediaType
+}
+
+// IsLanguage checks whether a header value should be compared as a language tag.
+func (s *SingleHeader) IsLanguage() bool {
+	return s.Language != nil && *s.Language
+}
+
+// IsAbsent checks whether the header must not be present in the request.
+func (s *SingleHeader) IsAbsent() bool {
+	return s.Absent != nil && *s.Absent
+}
+
+// IsStrip checks whether a header that must be absent is removed from the request instead of rejecting it.
+func (s *SingleHeader) IsStrip() bool {
+	return s.Strip != nil && *s.Strip
+}
+
+// IsNameRegex checks whether the name pattern is a regular expression instead of a glob.
+func (s *SingleHeader) IsNameRegex() bool {
+	return s.NameRegex != nil && *s.NameRegex
+}
+
+// matchModes returns the names of the value match modes that are enabled for the header.
+func (s *SingleHeader) matchModes() []string {
+	var modes []string
+
+	if s.IsContains() {
+		modes = append(modes, "contains")
+	}
+
+	if s.IsRegex() {
+		modes = append(modes, "regex")
+	}
+
+	if s.IsGlob() {
+		modes = append(modes, "glob")
+	}
+
+	if s.IsPrefix() {
+		modes = append(modes, "prefix")
+	}
+
+	if s.IsSuffix() {
+		modes = append(modes, "suffix")
+	}
+
+	if s.IsMediaType() {
+		modes = append(modes, "mediatype")
+	}
+
+	if s.IsLanguage() {
+		modes = append(modes, "language")
+	}
+
+	return modes
+}
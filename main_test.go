@@ -11,9 +11,12 @@ import (
 
 type Test struct {
 	name           string
+	method         string
+	url            string
 	headers        map[string]string
 	expectedStatus int
 	expectedError  error
+	expectedReason string
 }
 
 type TestConfig struct {
@@ -207,7 +210,7 @@ func TestValidator(t *testing.T) {
 					headers: map[string]string{
 						"Content-Language": "de-DE",
 					},
-					expectedError: fmt.Errorf("validate-headers: configuration incorrect for header Content-Language, match-all can only be used in combination with 'contains' or 'regex'"),
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].matchtype: match-all can only be used in combination with 'contains', 'regex', 'glob', 'prefix', 'suffix', 'mediatype' or 'language'"),
 				},
 			},
 		},
@@ -769,6 +772,144 @@ func TestValidator(t *testing.T) {
 				},
 			},
 		},
+		//MatchOnePrefixConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Authorization",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"Bearer ",
+						},
+						Required: Bool(true),
+						Debug:    Bool(true),
+						Prefix:   Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "MatchOnePrefix_Success",
+					headers: map[string]string{
+						"Authorization": "Bearer abc.def.ghi",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MatchOnePrefix_Fail_NotAtStart",
+					headers: map[string]string{
+						"Authorization": "Basic Bearer abc",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "MatchOnePrefix_Fail_InvalidHeader",
+					headers: map[string]string{
+						"InvalidHeader": "invalidValue",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		//MatchNoneSuffixConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Forwarded-Host",
+						MatchType: string(MatchNone),
+						Values: []string{
+							".internal",
+							".local",
+						},
+						Required: Bool(false),
+						Debug:    Bool(true),
+						Suffix:   Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "MatchNoneSuffix_Success",
+					headers: map[string]string{
+						"X-Forwarded-Host": "internal.example.com",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MatchNoneSuffix_Fail",
+					headers: map[string]string{
+						"X-Forwarded-Host": "db.internal",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "MatchNoneSuffix_Success_Missing",
+					headers: map[string]string{
+						"InvalidHeader": "invalidValue",
+					},
+					expectedStatus: http.StatusOK,
+				},
+			},
+		},
+		//MatchAllPrefixConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Authorization",
+						MatchType: string(MatchAll),
+						Values: []string{
+							"Bearer ",
+							"Bearer ey",
+						},
+						Prefix: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "MatchAllPrefix_Success",
+					headers: map[string]string{
+						"Authorization": "Bearer eyJhbGciOi",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MatchAllPrefix_Fail",
+					headers: map[string]string{
+						"Authorization": "Bearer abc",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// ConflictingMatchModes
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Content-Language",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"de",
+						},
+						Contains: Bool(true),
+						Regex:    Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "ConflictingMatchModes",
+					headers: map[string]string{
+						"Content-Language": "de-DE",
+					},
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0]: only one of 'contains', 'regex', 'glob', 'prefix', 'suffix', 'mediatype' or 'language' can be used, found contains, regex\n  warning: headers[0].values[0]: regex \"de\" is not anchored, it also matches values that only contain a match; use ^ and $ to match the whole value"),
+				},
+			},
+		},
 		// MissingHeadersConfig
 		{
 			config: CreateConfig(), //Using CreateConfig() to test the default config
@@ -778,7 +919,7 @@ func TestValidator(t *testing.T) {
 					headers: map[string]string{
 						"Content-Language": "de-DE",
 					},
-					expectedError: fmt.Errorf("validate-headers: configuration incorrect, missing headers"),
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers: missing headers"),
 				},
 			},
 		},
@@ -797,7 +938,7 @@ func TestValidator(t *testing.T) {
 					headers: map[string]string{
 						"Content-Language": "de-DE",
 					},
-					expectedError: fmt.Errorf("validate-headers: configuration incorrect, missing header name"),
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].name: missing header name\n  error: headers[0].values: missing header values"),
 				},
 			},
 		},
@@ -820,7 +961,7 @@ func TestValidator(t *testing.T) {
 					headers: map[string]string{
 						"Content-Language": "de-DE",
 					},
-					expectedError: fmt.Errorf("validate-headers: configuration incorrect, missing match type configuration for header Content-Language"),
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].matchtype: missing match type configuration"),
 				},
 			},
 		},
@@ -840,7 +981,7 @@ func TestValidator(t *testing.T) {
 					headers: map[string]string{
 						"Content-Language": "de-DE",
 					},
-					expectedError: fmt.Errorf("validate-headers: configuration incorrect, missing header values"),
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].values: missing header values"),
 				},
 			},
 		},
@@ -863,7 +1004,31 @@ func TestValidator(t *testing.T) {
 					headers: map[string]string{
 						"Content-Language": "de-DE",
 					},
-					expectedError: fmt.Errorf("validate-headers: configuration incorrect, empty value found"),
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].values[0]: empty value found"),
+				},
+			},
+		},
+		// EveryHeaderProblem
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Api-Key",
+						MatchType: string(MatchOne),
+					},
+					{
+						Name:      "Content-Language",
+						MatchType: string(MatchOne),
+						Values: []string{
+							" ",
+						},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "EveryHeaderProblem",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].values: missing header values\n  error: headers[1].values[0]: empty value found"),
 				},
 			},
 		},
@@ -893,6 +1058,13 @@ func TestValidator(t *testing.T) {
 		},
 	}
 
+	runValidatorTests(t, configTestPairs)
+}
+
+// runValidatorTests creates a validator for every config and runs the paired requests against it.
+func runValidatorTests(t *testing.T, configTestPairs []TestConfig) {
+	t.Helper()
+
 	// Test case execution
 	for _, ct := range configTestPairs {
 		for _, tt := range ct.tests {
@@ -904,7 +1076,16 @@ func TestValidator(t *testing.T) {
 			// }
 
 			t.Run(n, func(t *testing.T) {
-				req, err := http.NewRequest("GET", "/", nil)
+				method, url := tt.method, tt.url
+				if method == "" {
+					method = http.MethodGet
+				}
+
+				if url == "" {
+					url = "/"
+				}
+
+				req, err := http.NewRequest(method, url, nil)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -915,9 +1096,16 @@ func TestValidator(t *testing.T) {
 
 				rr := httptest.NewRecorder()
 
+				// ValidateConfig reports errors for exactly the configurations that New rejects.
+				diagnosticsErr := ValidateConfig(ct.config).Err()
+
 				h, err := New(nil, http.HandlerFunc(dummyHandler), ct.config, "test")
+				if (err == nil) != (diagnosticsErr == nil) {
+					t.Errorf("New returned %v, but ValidateConfig returned %v", err, diagnosticsErr)
+				}
+
 				if err != nil {
-					if err.Error() != tt.expectedError.Error() {
+					if tt.expectedError == nil || err.Error() != tt.expectedError.Error() {
 						t.Fatal(err)
 					}
 
@@ -929,6 +1117,10 @@ func TestValidator(t *testing.T) {
 				if rr.Code != tt.expectedStatus {
 					t.Errorf("got %d, want %d", rr.Code, tt.expectedStatus)
 				}
+
+				if reason := rr.Header().Get(ct.config.Error.ReasonHeader); tt.expectedReason != "" && reason != tt.expectedReason {
+					t.Errorf("got reason %q, want %q", reason, tt.expectedReason)
+				}
 			})
 		}
 	}
@@ -0,0 +1,152 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestParseMatchType(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  MatchType
+		expectErr bool
+	}{
+		{value: "all", expected: MatchAll},
+		{value: "ALL", expected: MatchAll},
+		{value: " One ", expected: MatchOne},
+		{value: "ANY", expected: MatchOne},
+		{value: "none", expected: MatchNone},
+		{value: "atLeast", expected: MatchAtLeast},
+		{value: "AtMost", expected: MatchAtMost},
+		{value: "exactly", expected: MatchExactly},
+		{value: "score", expectErr: true},
+		{value: "nnone", expectErr: true},
+		{value: "", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			actual, err := ParseMatchType(tt.value, headerMatchTypes)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", actual)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual != tt.expected {
+				t.Errorf("got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchTypeValidation(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//MatchTypeAliasConfig
+		{
+			config: &Config{
+				MatchType: "ALL",
+				Headers: []SingleHeader{
+					{
+						Name:      "Content-Language",
+						MatchType: "NONE",
+						Values: []string{
+							"de",
+						},
+						Contains: Bool(true),
+					},
+					{
+						Name:      "X-Api-Key",
+						MatchType: "Any",
+						Values: []string{
+							"secret",
+						},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "MatchTypeAlias_Success",
+					headers: map[string]string{
+						"Content-Language": "en-GB",
+						"X-Api-Key":        "secret",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "MatchTypeAlias_Fail_Blacklisted",
+					headers: map[string]string{
+						"Content-Language": "de-DE",
+						"X-Api-Key":        "secret",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// UnknownMatchType
+		{
+			config: &Config{
+				MatchType: "every",
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Api-Key",
+						MatchType: string(MatchOne),
+						Values:    []string{"secret"},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "UnknownMatchType",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: matchtype: unknown matchtype \"every\", allowed: all, one, none, atleast, atmost, exactly, score"),
+				},
+			},
+		},
+		// UnknownHeaderMatchType
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Content-Language",
+						MatchType: "nnone",
+						Values:    []string{"de"},
+						Contains:  Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "UnknownHeaderMatchType",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].matchtype: unknown matchtype \"nnone\", allowed: all, one, none, atleast, atmost, exactly"),
+				},
+			},
+		},
+		// ScoreHeaderMatchType
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Content-Language",
+						MatchType: string(MatchScore),
+						Values:    []string{"de"},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "ScoreHeaderMatchType",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].matchtype: unknown matchtype \"score\", allowed: all, one, none, atleast, atmost, exactly"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
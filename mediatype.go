@@ -0,0 +1,208 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// acceptHeader is the header whose value is evaluated as a weighted list of media ranges.
+const acceptHeader = "Accept"
+
+// mediaRange is a parsed media type or media range such as `application/json; charset=utf-8`,
+// `application/*+json` or `*/*;q=0.5`.
+type mediaRange struct {
+	mainType string
+	subType  string
+	params   map[string]string
+	quality  float64
+}
+
+// parseMediaRange parses a media type with mime.ParseMediaType. The `q` parameter is removed from
+// the parameters and returned as the quality, which defaults to 1.
+func parseMediaRange(value string) (mediaRange, error) {
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return mediaRange{}, fmt.Errorf("invalid media type %q: %w", value, err)
+	}
+
+	mainType, subType, found := strings.Cut(mediaType, "/")
+	if !found || subType == "" {
+		return mediaRange{}, fmt.Errorf("invalid media type %q, expected type/subtype", value)
+	}
+
+	if mainType == "*" && subType != "*" && !strings.HasPrefix(subType, "*+") {
+		return mediaRange{}, fmt.Errorf("invalid media type %q, a wildcard type requires a wildcard subtype", value)
+	}
+
+	quality := 1.0
+
+	if rawQuality, ok := params["q"]; ok {
+		quality, err = strconv.ParseFloat(rawQuality, 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return mediaRange{}, fmt.Errorf("invalid quality %q in media type %q", rawQuality, value)
+		}
+
+		delete(params, "q")
+	}
+
+	return mediaRange{mainType: mainType, subType: subType, params: params, quality: quality}, nil
+}
+
+// compileMediaRanges parses the configured media types.
+func compileMediaRanges(values []string) ([]mediaRange, error) {
+	ranges := make([]mediaRange, 0, len(values))
+
+	for _, value := range values {
+		configured, err := parseMediaRange(value)
+		if err != nil {
+			return nil, err
+		}
+
+		ranges = append(ranges, configured)
+	}
+
+	return ranges, nil
+}
+
+// matchesSubType compares subtypes. A `*` matches any subtype and `*+suffix` matches any subtype
+// with that structured syntax suffix, e.g. `*+json` matches `vnd.api+json` but `json` doesn't.
+func matchesSubType(pattern, subType string) bool {
+	if pattern == "*" || pattern == subType {
+		return true
+	}
+
+	if suffix := strings.TrimPrefix(pattern, "*"); suffix != pattern {
+		return strings.HasSuffix(subType, suffix) && len(subType) > len(suffix)
+	}
+
+	return false
+}
+
+// matches checks whether the media type satisfies the configured media range. Every configured
+// parameter must be present with the same (case-insensitive) value.
+func (r mediaRange) matches(mediaType mediaRange) bool {
+	if r.mainType != "*" && r.mainType != mediaType.mainType {
+		return false
+	}
+
+	if !matchesSubType(r.subType, mediaType.subType) {
+		return false
+	}
+
+	for key, value := range r.params {
+		if !strings.EqualFold(mediaType.params[key], value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// accepts checks whether an Accept range covers the configured media range and returns the specificity
+// of the range; the most specific matching range determines the quality.
+func (r mediaRange) accepts(configured mediaRange) (bool, int) {
+	specificity := 0
+
+	switch {
+	case r.mainType == "*" && r.subType == "*":
+	case r.mainType != configured.mainType && configured.mainType != "*":
+		return false, 0
+	case r.subType == "*":
+		specificity = 1
+	case matchesSubType(configured.subType, r.subType):
+		specificity = 2
+	default:
+		return false, 0
+	}
+
+	// A range with parameters only covers media types with the same parameters (RFC 9110, section 12.5.1).
+	for key, value := range r.params {
+		if configuredValue, ok := configured.params[key]; !ok || !strings.EqualFold(configuredValue, value) {
+			return false, 0
+		}
+
+		specificity++
+	}
+
+	return true, specificity
+}
+
+// parseRequestMediaTypes parses a Content-Type like value, or for the Accept header a list of media ranges.
+// Request values that can't be parsed are rejected as malformed before matching.
+func parseRequestMediaTypes(requestValue string, vHeader *SingleHeader) ([]mediaRange, error) {
+	if !strings.EqualFold(vHeader.Name, acceptHeader) {
+		mediaType, err := parseMediaRange(requestValue)
+		if err != nil {
+			return nil, err
+		}
+
+		return []mediaRange{mediaType}, nil
+	}
+
+	var accepted []mediaRange
+
+	for _, element := range strings.Split(requestValue, ",") {
+		if strings.TrimSpace(element) == "" {
+			continue
+		}
+
+		acceptRange, err := parseMediaRange(element)
+		if err != nil {
+			return nil, err
+		}
+
+		accepted = append(accepted, acceptRange)
+	}
+
+	return accepted, nil
+}
+
+// checkMediaType checks whether a Content-Type like value matches the configured media types. For the
+// Accept header the value is a list of media ranges and a configured type matches when the client
+// accepts it with a quality above zero. requestValues rejects values that can't be parsed, so they
+// never reach the matcher.
+func checkMediaType(requestValue string, vHeader *SingleHeader) bool {
+	if vHeader.IsDebug() {
+		fmt.Println("validate-headers (debug): Validating media type:", requestValue, vHeader.Values)
+	}
+
+	parsed, err := parseRequestMediaTypes(requestValue, vHeader)
+	if err != nil {
+		return false
+	}
+
+	if strings.EqualFold(vHeader.Name, acceptHeader) {
+		return checkAccept(parsed, vHeader)
+	}
+
+	matchCount := 0
+	for _, configured := range vHeader.mediaRanges {
+		if configured.matches(parsed[0]) {
+			matchCount++
+		}
+	}
+
+	return checkMatchCount(matchCount, vHeader)
+}
+
+// checkAccept counts the configured media types that are acceptable according to the Accept ranges.
+func checkAccept(accepted []mediaRange, vHeader *SingleHeader) bool {
+	matchCount := 0
+	for _, configured := range vHeader.mediaRanges {
+		quality, best := 0.0, -1
+
+		for _, acceptRange := range accepted {
+			if ok, specificity := acceptRange.accepts(configured); ok && specificity > best {
+				quality, best = acceptRange.quality, specificity
+			}
+		}
+
+		if quality > 0 {
+			matchCount++
+		}
+	}
+
+	return checkMatchCount(matchCount, vHeader)
+}
@@ -0,0 +1,292 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestMediaTypeMatches(t *testing.T) {
+	tests := []struct {
+		configured string
+		value      string
+		expected   bool
+	}{
+		{configured: "application/json", value: "application/json", expected: true},
+		{configured: "application/json", value: "Application/JSON; charset=utf-8", expected: true},
+		{configured: "application/json", value: "application/json-patch+json", expected: false},
+		{configured: "application/json", value: "application/vnd.api+json", expected: false},
+		{configured: "application/*+json", value: "application/vnd.api+json", expected: true},
+		{configured: "application/*+json", value: "application/json", expected: false},
+		{configured: "*/*+json", value: "text/x.custom+json", expected: true},
+		{configured: "application/*", value: "application/xml", expected: true},
+		{configured: "application/*", value: "text/xml", expected: false},
+		{configured: "*/*", value: "image/png", expected: true},
+		{configured: "application/json; charset=utf-8", value: "application/json; charset=UTF-8", expected: true},
+		{configured: "application/json; charset=utf-8", value: "application/json; charset=latin1", expected: false},
+		{configured: "application/json; charset=utf-8", value: "application/json", expected: false},
+		{configured: "application/json", value: "*/*", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.configured+"_"+tt.value, func(t *testing.T) {
+			configured, err := parseMediaRange(tt.configured)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			value, err := parseMediaRange(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if configured.matches(value) != tt.expected {
+				t.Errorf("got %v, want %v", !tt.expected, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMediaRangeAccepts(t *testing.T) {
+	tests := []struct {
+		acceptRange string
+		configured  string
+		expected    bool
+	}{
+		{acceptRange: "text/html", configured: "text/html", expected: true},
+		{acceptRange: "text/html;level=1", configured: "text/html", expected: false},
+		{acceptRange: "text/html;level=1", configured: "text/html;level=1", expected: true},
+		{acceptRange: "text/html;level=1", configured: "text/html;level=2", expected: false},
+		{acceptRange: "text/html", configured: "text/html;level=1", expected: true},
+		{acceptRange: "text/*", configured: "text/html", expected: true},
+		{acceptRange: "*/*", configured: "image/png", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptRange+"_"+tt.configured, func(t *testing.T) {
+			acceptRange, err := parseMediaRange(tt.acceptRange)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			configured, err := parseMediaRange(tt.configured)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ok, _ := acceptRange.accepts(configured); ok != tt.expected {
+				t.Errorf("got %v, want %v", ok, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseMediaRangeInvalid(t *testing.T) {
+	for _, value := range []string{"json", "application/", "*/json", "text/html; q=2", "text/html; q=high", "text/html; charset"} {
+		if _, err := parseMediaRange(value); err == nil {
+			t.Errorf("expected an error for media type %q", value)
+		}
+	}
+}
+
+func TestMediaType(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//ContentTypeConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Content-Type",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"application/json; charset=utf-8",
+							"application/*+json; charset=utf-8",
+						},
+						MediaType: Bool(true),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "ContentType_Success",
+					headers: map[string]string{
+						"Content-Type": "application/json;charset=UTF-8",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ContentType_Success_Suffix",
+					headers: map[string]string{
+						"Content-Type": "application/merge-patch+json; charset=utf-8",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ContentType_Fail_Charset",
+					headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Content-Type: mismatch",
+				},
+				{
+					name: "ContentType_Fail_LookAlike",
+					headers: map[string]string{
+						"Content-Type": "application/jsonx; charset=utf-8",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Content-Type: mismatch",
+				},
+				{
+					name: "ContentType_Fail_Unparsable",
+					headers: map[string]string{
+						"Content-Type": "application/json; charset",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Content-Type: malformed",
+				},
+			},
+		},
+		//ContentTypeBlacklistConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Content-Type",
+						MatchType: string(MatchNone),
+						Values: []string{
+							"multipart/*",
+						},
+						MediaType: Bool(true),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "ContentTypeBlacklist_Success",
+					headers: map[string]string{
+						"Content-Type": "application/json",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ContentTypeBlacklist_Fail_Blacklisted",
+					headers: map[string]string{
+						"Content-Type": "Multipart/Form-Data; boundary=abc",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Content-Type: mismatch",
+				},
+				{
+					name: "ContentTypeBlacklist_Fail_Unparsable",
+					headers: map[string]string{
+						"Content-Type": "garbage;;",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Content-Type: malformed",
+				},
+			},
+		},
+		//AcceptConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Accept",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"application/json",
+							"application/problem+json",
+						},
+						MediaType: Bool(true),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "Accept_Success",
+					headers: map[string]string{
+						"Accept": "text/html, application/json;q=0.9",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Accept_Success_Wildcard",
+					headers: map[string]string{
+						"Accept": "text/html, */*;q=0.1",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Accept_Success_SubtypeWildcard",
+					headers: map[string]string{
+						"Accept": "application/*",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Accept_Fail_NotAccepted",
+					headers: map[string]string{
+						"Accept": "text/html, application/xml",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Accept: mismatch",
+				},
+				{
+					name: "Accept_Fail_ExcludedBySpecificRange",
+					headers: map[string]string{
+						"Accept": "application/json;q=0, application/problem+json;q=0, */*",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Accept: mismatch",
+				},
+				{
+					name: "Accept_Fail_RangeParameter",
+					headers: map[string]string{
+						"Accept": "application/json;version=2",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Accept: mismatch",
+				},
+				{
+					name: "Accept_Fail_Unparsable",
+					headers: map[string]string{
+						"Accept": "application/json;q=abc",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Accept: malformed",
+				},
+			},
+		},
+		// InvalidMediaType
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Content-Type",
+						MatchType: string(MatchOne),
+						Values:    []string{"json"},
+						MediaType: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "InvalidMediaType",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].values[0]: invalid media type \"json\", expected type/subtype"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
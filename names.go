@@ -0,0 +1,89 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	// NameResolutionExact looks up headers by their configured name only; the default.
+	NameResolutionExact = "exact"
+	// NameResolutionUnderscoreAsDash treats underscores and dashes in header names as equivalent.
+	NameResolutionUnderscoreAsDash = "treat-underscore-as-dash"
+	// NameResolutionRejectAmbiguous treats underscores and dashes as equivalent and rejects requests
+	// that contain more than one spelling of a header, e.g. both `X_User` and `X-User`.
+	NameResolutionRejectAmbiguous = "reject-ambiguous"
+)
+
+// validateNameResolution checks whether the name resolution policy is supported.
+func validateNameResolution(policy string) error {
+	switch policy {
+	case "", NameResolutionExact, NameResolutionUnderscoreAsDash, NameResolutionRejectAmbiguous:
+		return nil
+	default:
+		return fmt.Errorf("unknown nameresolution %q, allowed: %s, %s, %s", policy, NameResolutionExact, NameResolutionUnderscoreAsDash, NameResolutionRejectAmbiguous)
+	}
+}
+
+// isExactNameResolution checks whether header names are looked up without normalization.
+func isExactNameResolution(policy string) bool {
+	return policy == "" || policy == NameResolutionExact
+}
+
+// normalizeName returns the header name with underscores replaced by dashes.
+func normalizeName(name string) string {
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// equivalentNames returns the names of the request headers that resolve to the name according to the policy,
+// with the configured spelling first. With exact resolution this is the configured name when it is present.
+func equivalentNames(req *http.Request, name string, policy string) []string {
+	canonical := http.CanonicalHeaderKey(name)
+
+	if isExactNameResolution(policy) {
+		if len(req.Header.Values(name)) == 0 {
+			return nil
+		}
+
+		return []string{name}
+	}
+
+	var names []string
+
+	for key, values := range req.Header {
+		if len(values) > 0 && strings.EqualFold(normalizeName(key), normalizeName(name)) {
+			names = append(names, key)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == canonical || names[j] == canonical {
+			return names[i] == canonical
+		}
+
+		return names[i] < names[j]
+	})
+
+	return names
+}
+
+// headerValue returns the first value of the header according to the name resolution policy. With
+// 'reject-ambiguous' a header that is present with more than one spelling fails as ambiguous.
+func headerValue(req *http.Request, name string, policy string) (string, *Failure) {
+	if isExactNameResolution(policy) {
+		return req.Header.Get(name), nil
+	}
+
+	names := equivalentNames(req, name, policy)
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	if len(names) > 1 && policy == NameResolutionRejectAmbiguous {
+		return "", &Failure{Header: name, Reason: ReasonAmbiguous, Detail: strings.Join(names, ", ")}
+	}
+
+	return req.Header[names[0]][0], nil
+}
@@ -0,0 +1,223 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNameResolution(t *testing.T) {
+	matchOneRequired := func(policy string) *Config {
+		return &Config{
+			NameResolution: policy,
+			Headers: []SingleHeader{
+				{
+					Name:      "MATCH_ONE_REQUIRED",
+					MatchType: string(MatchOne),
+					Values: []string{
+						"test",
+					},
+				},
+				{
+					Name:   "X-Internal-User",
+					Absent: Bool(true),
+				},
+			},
+			Error: ErrorConfig{
+				ReasonHeader: "X-Validation-Reason",
+			},
+		}
+	}
+
+	configTestPairs := []TestConfig{
+		//ExactConfig
+		{
+			config: matchOneRequired(NameResolutionExact),
+			tests: []Test{
+				{
+					name: "Exact_Success",
+					headers: map[string]string{
+						"MATCH_ONE_REQUIRED": "test",
+						"X_Internal_User":    "admin",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Exact_Fail_DashSpelling",
+					headers: map[string]string{
+						"MATCH-ONE-REQUIRED": "test",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "MATCH_ONE_REQUIRED: missing",
+				},
+			},
+		},
+		//UnderscoreAsDashConfig
+		{
+			config: matchOneRequired(NameResolutionUnderscoreAsDash),
+			tests: []Test{
+				{
+					name: "UnderscoreAsDash_Success_DashSpelling",
+					headers: map[string]string{
+						"Match-One-Required": "test",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "UnderscoreAsDash_Success_PrefersConfiguredSpelling",
+					headers: map[string]string{
+						"MATCH_ONE_REQUIRED": "test",
+						"Match-One-Required": "other",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "UnderscoreAsDash_Fail_AbsentUnderscoreSpelling",
+					headers: map[string]string{
+						"MATCH_ONE_REQUIRED": "test",
+						"X_INTERNAL_USER":    "admin",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X_internal_user: forbidden",
+				},
+			},
+		},
+		//RejectAmbiguousConfig
+		{
+			config: matchOneRequired(NameResolutionRejectAmbiguous),
+			tests: []Test{
+				{
+					name: "RejectAmbiguous_Success",
+					headers: map[string]string{
+						"Match-One-Required": "test",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "RejectAmbiguous_Fail_BothSpellings",
+					headers: map[string]string{
+						"MATCH_ONE_REQUIRED": "test",
+						"Match-One-Required": "test",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "MATCH_ONE_REQUIRED: ambiguous",
+				},
+			},
+		},
+		// UnknownNameResolution
+		{
+			config: matchOneRequired("lenient"),
+			tests: []Test{
+				{
+					name:          "UnknownNameResolution",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: nameresolution: unknown nameresolution \"lenient\", allowed: exact, treat-underscore-as-dash, reject-ambiguous"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
+
+func TestNameResolutionFamily(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//RejectAmbiguousFamilyConfig
+		{
+			config: &Config{
+				NameResolution: NameResolutionRejectAmbiguous,
+				Headers: []SingleHeader{
+					{
+						NamePattern: "X-Custom-*",
+						MaxLength:   8,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "RejectAmbiguousFamily_Success_Underscore",
+					headers: map[string]string{
+						"X_Custom_A": "short",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "RejectAmbiguousFamily_Fail_UnderscoreTooLong",
+					headers: map[string]string{
+						"X_Custom_A": "much too long",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "RejectAmbiguousFamily_Fail_BothSpellings",
+					headers: map[string]string{
+						"X_Custom_A": "short",
+						"X-Custom-A": "short",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
+
+func TestNameResolutionRules(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//RejectAmbiguousRuleConfig
+		{
+			config: &Config{
+				NameResolution: NameResolutionRejectAmbiguous,
+				Rules: []ConditionalRule{
+					{
+						If: []SingleHeader{
+							{
+								Name:      "X-Client-Type",
+								MatchType: string(MatchOne),
+								Values:    []string{"partner"},
+							},
+						},
+						Then: []SingleHeader{
+							{
+								Name:   "X-Partner-Id",
+								Format: "uuid",
+							},
+						},
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "RejectAmbiguousRule_Success_Underscore",
+					headers: map[string]string{
+						"X_Client_Type": "partner",
+						"X-Partner-Id":  "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "RejectAmbiguousRule_Fail_UnderscoreCondition",
+					headers: map[string]string{
+						"X_Client_Type": "partner",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Partner-Id: missing",
+				},
+				{
+					name: "RejectAmbiguousRule_Fail_BothSpellings",
+					headers: map[string]string{
+						"X-Client-Type": "public",
+						"X_Client_Type": "partner",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Client-Type: ambiguous",
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
@@ -0,0 +1,85 @@
+package traefik_plugin_validate_headers_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	validateheaders "github.com/frankforpresident/traefik-plugin-validate-headers"
+	"github.com/frankforpresident/traefik-plugin-validate-headers/internal/policytest"
+)
+
+// TestPolicies runs the YAML fixtures in testdata/policies: every file holds a plugin configuration and
+// requests with their expected status and, optionally, reason.
+func TestPolicies(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "policies", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("no policy fixtures found")
+	}
+
+	for _, file := range files {
+		file := file
+
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".yaml"), func(t *testing.T) {
+			config, cases, err := policytest.LoadFixture(file)
+			if err != nil {
+				t.Fatalf("%s: %v", file, err)
+			}
+
+			diagnostics := validateheaders.ValidateConfig(config)
+			for _, warning := range diagnostics.Warnings() {
+				t.Log(warning)
+			}
+
+			if err := diagnostics.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := policytest.Run(config, cases)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := range results {
+				result := &results[i]
+
+				t.Run(result.Case.Name, func(t *testing.T) {
+					if !result.Passed() {
+						t.Errorf("got %d %q, want %d %q", result.Status, result.Reason, result.Case.ExpectedStatus(), result.Case.Reason)
+					}
+				})
+			}
+		})
+	}
+}
+
+// TestTraefikTestData checks that the testData of the plugin manifest, which the plugin catalog loads, is a
+// valid configuration without warnings.
+func TestTraefikTestData(t *testing.T) {
+	data, err := os.ReadFile(".traefik.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := policytest.LoadConfig(data, policytest.DefaultPlugin, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Headers) == 0 {
+		t.Fatal("missing testData headers in .traefik.yml")
+	}
+
+	if diagnostics := validateheaders.ValidateConfig(config); len(diagnostics) > 0 {
+		t.Errorf("testData in .traefik.yml:\n%v", diagnostics)
+	}
+
+	if _, err := policytest.Run(config, nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,264 @@
+package traefik_plugin_validate_headers
+
+import (
+	"strings"
+)
+
+// Match modes of the generated policies.
+const (
+	modeExact    = "exact"
+	modeContains = "contains"
+	modePrefix   = "prefix"
+	modeSuffix   = "suffix"
+	modeRegex    = "regex"
+)
+
+var fuzzModes = []string{modeExact, modeContains, modePrefix, modeSuffix, modeRegex}
+
+// fuzzHeader is a generated header configuration; values only use the letters 'a' and 'b', and regex values
+// are such a literal with optional anchors, so the reference evaluator can match them without regexes.
+type fuzzHeader struct {
+	name      string
+	matchType MatchType
+	mode      string
+	values    []string
+	required  bool
+}
+
+// fuzzPolicy is a generated plugin configuration.
+type fuzzPolicy struct {
+	matchType MatchType
+	headers   []fuzzHeader
+}
+
+// fuzzInput turns fuzz data into the decisions of a generated policy and request. An exhausted input returns
+// zero, so every input results in a valid policy.
+type fuzzInput struct {
+	data []byte
+}
+
+func (in *fuzzInput) next(n int) int {
+	if len(in.data) == 0 {
+		return 0
+	}
+
+	b := in.data[0]
+	in.data = in.data[1:]
+
+	return int(b) % n
+}
+
+func (in *fuzzInput) text(minLen, maxLen int) string {
+	n := minLen + in.next(maxLen-minLen+1)
+
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteByte("ab"[in.next(2)])
+	}
+
+	return sb.String()
+}
+
+// generatePolicy generates a valid policy of one to three headers.
+func generatePolicy(in *fuzzInput) *fuzzPolicy {
+	policy := &fuzzPolicy{matchType: []MatchType{MatchAll, MatchOne, MatchNone}[in.next(3)]}
+
+	count := 1 + in.next(3)
+	for i := 0; i < count; i++ {
+		h := fuzzHeader{
+			name:      []string{"X-A", "X-B", "X-C"}[i],
+			matchType: []MatchType{MatchAll, MatchOne, MatchNone}[in.next(3)],
+			mode:      fuzzModes[in.next(len(fuzzModes))],
+			required:  in.next(2) == 0,
+		}
+
+		// Match-all needs a match mode.
+		if h.mode == modeExact && h.matchType == MatchAll {
+			h.matchType = MatchOne
+		}
+
+		values := 1 + in.next(3)
+		for j := 0; j < values; j++ {
+			value := in.text(1, 3)
+
+			if h.mode == modeRegex {
+				switch in.next(4) {
+				case 1:
+					value = "^" + value
+				case 2:
+					value += "$"
+				case 3:
+					value = "^" + value + "$"
+				}
+			}
+
+			h.values = append(h.values, value)
+		}
+
+		policy.headers = append(policy.headers, h)
+	}
+
+	return policy
+}
+
+// generateRequest generates the values of the headers of the policy; a missing header has no value.
+func generateRequest(in *fuzzInput, policy *fuzzPolicy) map[string]string {
+	request := make(map[string]string)
+
+	for _, h := range policy.headers {
+		if value := in.text(0, 4); value != "" {
+			request[h.name] = value
+		}
+	}
+
+	return request
+}
+
+// config returns the plugin configuration of the policy.
+func (p *fuzzPolicy) config() *Config {
+	config := &Config{
+		MatchType: string(p.matchType),
+		Error:     ErrorConfig{ReasonHeader: "X-Reason"},
+	}
+
+	for _, h := range p.headers {
+		vHeader := SingleHeader{
+			Name:      h.name,
+			MatchType: string(h.matchType),
+			Values:    append([]string(nil), h.values...),
+			Required:  Bool(h.required),
+		}
+
+		switch h.mode {
+		case modeContains:
+			vHeader.Contains = Bool(true)
+		case modePrefix:
+			vHeader.Prefix = Bool(true)
+		case modeSuffix:
+			vHeader.Suffix = Bool(true)
+		case modeRegex:
+			vHeader.Regex = Bool(true)
+		}
+
+		config.Headers = append(config.Headers, vHeader)
+	}
+
+	return config
+}
+
+// referenceMatches checks a single configured value against the request value with the match mode.
+func referenceMatches(mode, configured, value string) bool {
+	switch mode {
+	case modeContains:
+		return strings.Contains(value, configured)
+	case modePrefix:
+		return strings.HasPrefix(value, configured)
+	case modeSuffix:
+		return strings.HasSuffix(value, configured)
+	case modeRegex:
+		literal := strings.TrimSuffix(strings.TrimPrefix(configured, "^"), "$")
+
+		switch {
+		case strings.HasPrefix(configured, "^") && strings.HasSuffix(configured, "$"):
+			return value == literal
+		case strings.HasPrefix(configured, "^"):
+			return strings.HasPrefix(value, literal)
+		case strings.HasSuffix(configured, "$"):
+			return strings.HasSuffix(value, literal)
+		default:
+			return strings.Contains(value, literal)
+		}
+	default:
+		return value == configured
+	}
+}
+
+// referenceHeader checks a present header: with 'none' no configured value may match, with 'all' every
+// configured value has to match and with 'one' at least one.
+func referenceHeader(h *fuzzHeader, mode, value string) bool {
+	matched := 0
+
+	for _, configured := range h.values {
+		if referenceMatches(mode, configured, value) {
+			matched++
+		}
+	}
+
+	switch h.matchType {
+	case MatchNone:
+		return matched == 0
+	case MatchAll:
+		return matched == len(h.values)
+	default:
+		return matched > 0
+	}
+}
+
+// referenceEvaluate decides a request as documented for the plugin match types, independent of the matchers
+// of the plugin. It returns the reason that is exposed in the reason header; empty when the request is allowed.
+//
+//   - all: every present header has to match, and every missing header must not be required.
+//   - one: at least one present header has to match. A missing required header resets the outcome, so a
+//     header after it has to match again; the reason is the first mismatch or the last missing header.
+//   - none: no present header may match; headers are compared by their exact values, whatever their
+//     match mode, and 'all' is checked like 'one'. Missing headers are allowed, even when required.
+func referenceEvaluate(policy *fuzzPolicy, request map[string]string) string {
+	switch policy.matchType {
+	case MatchOne:
+		valid := false
+		reason := ""
+
+		for i := range policy.headers {
+			h := &policy.headers[i]
+
+			value, ok := request[h.name]
+
+			switch {
+			case ok && referenceHeader(h, h.mode, value):
+				valid = true
+			case ok && reason == "":
+				reason = h.name + ": mismatch"
+			case !ok && h.required:
+				valid = false
+				reason = h.name + ": missing"
+			}
+		}
+
+		switch {
+		case valid:
+			return ""
+		case reason == "":
+			return "missing"
+		default:
+			return reason
+		}
+	case MatchNone:
+		for i := range policy.headers {
+			h := policy.headers[i]
+			if h.matchType == MatchAll {
+				h.matchType = MatchOne
+			}
+
+			if value, ok := request[h.name]; ok && !referenceHeader(&h, modeExact, value) {
+				return h.name + ": mismatch"
+			}
+		}
+
+		return ""
+	default:
+		for i := range policy.headers {
+			h := &policy.headers[i]
+
+			value, ok := request[h.name]
+			if ok && !referenceHeader(h, h.mode, value) {
+				return h.name + ": mismatch"
+			}
+
+			if !ok && h.required {
+				return h.name + ": missing"
+			}
+		}
+
+		return ""
+	}
+}
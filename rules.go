@@ -0,0 +1,74 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ConditionalRule validates the 'then' headers when all 'if' headers match, and the 'else' headers otherwise.
+type ConditionalRule struct {
+	If   []SingleHeader `json:"if,omitempty"`
+	Then []SingleHeader `json:"then,omitempty"`
+	Else []SingleHeader `json:"else,omitempty"`
+}
+
+// compileRules compiles the headers of the conditional rules, which ValidateConfig has checked.
+func compileRules(rules []ConditionalRule, nameResolution string) error {
+	for i := range rules {
+		branches := []struct {
+			name    string
+			headers []SingleHeader
+		}{
+			{name: "if", headers: rules[i].If},
+			{name: "then", headers: rules[i].Then},
+			{name: "else", headers: rules[i].Else},
+		}
+
+		for _, branch := range branches {
+			for j := range branch.headers {
+				path := fmt.Sprintf("rules[%d].%s[%d]", i, branch.name, j)
+				if err := compileHeader(path, &branch.headers[j], nameResolution).Err(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRules checks the conditional rules in order. A condition holds when every 'if' header passes as with
+// match type 'all'; the headers of the selected branch are then checked the same way. Only an 'if' header that
+// is missing, doesn't match or, for absent headers, is present makes the condition false; any other failure,
+// such as a malformed or ambiguous header, rejects the request.
+func checkRules(rules []ConditionalRule, req *http.Request) *Failure {
+	for i := range rules {
+		rule := &rules[i]
+
+		branch := rule.Then
+
+		if failure := checkAll(rule.If, req); failure != nil {
+			if !isConditionFalse(failure) {
+				return failure
+			}
+
+			branch = rule.Else
+		}
+
+		if failure := checkAll(branch, req); failure != nil {
+			return failure
+		}
+	}
+
+	return nil
+}
+
+// isConditionFalse checks whether the failure of an 'if' header means that the condition doesn't hold.
+func isConditionFalse(failure *Failure) bool {
+	switch failure.Reason {
+	case ReasonMissing, ReasonMismatch, ReasonForbidden:
+		return true
+	default:
+		return false
+	}
+}
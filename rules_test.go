@@ -0,0 +1,298 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestConditionalRules(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//PartnerRuleConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Client-Type",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"partner",
+							"public",
+						},
+					},
+				},
+				Rules: []ConditionalRule{
+					{
+						If: []SingleHeader{
+							{
+								Name:      "X-Client-Type",
+								MatchType: string(MatchOne),
+								Values: []string{
+									"partner",
+								},
+							},
+						},
+						Then: []SingleHeader{
+							{
+								Name:   "X-Partner-Id",
+								Format: "uuid",
+							},
+						},
+						Else: []SingleHeader{
+							{
+								Name:      "X-Partner-Id",
+								MatchType: string(MatchNone),
+								Values: []string{
+									"*",
+								},
+								Glob:     Bool(true),
+								Required: Bool(false),
+							},
+						},
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "PartnerRule_Success_Partner",
+					headers: map[string]string{
+						"X-Client-Type": "partner",
+						"X-Partner-Id":  "123e4567-e89b-12d3-a456-426614174000",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "PartnerRule_Success_Public",
+					headers: map[string]string{
+						"X-Client-Type": "public",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "PartnerRule_Fail_PartnerIdMissing",
+					headers: map[string]string{
+						"X-Client-Type": "partner",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Partner-Id: missing",
+				},
+				{
+					name: "PartnerRule_Fail_PartnerIdMalformed",
+					headers: map[string]string{
+						"X-Client-Type": "partner",
+						"X-Partner-Id":  "42",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Partner-Id: malformed",
+				},
+				{
+					name: "PartnerRule_Fail_PublicWithPartnerId",
+					headers: map[string]string{
+						"X-Client-Type": "public",
+						"X-Partner-Id":  "123e4567-e89b-12d3-a456-426614174000",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Partner-Id: mismatch",
+				},
+				{
+					name: "PartnerRule_Fail_HeadersCheckedFirst",
+					headers: map[string]string{
+						"X-Client-Type": "internal",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Client-Type: mismatch",
+				},
+			},
+		},
+		//WebSocketRuleConfig
+		{
+			config: &Config{
+				Rules: []ConditionalRule{
+					{
+						If: []SingleHeader{
+							{
+								Name:      "Upgrade",
+								MatchType: string(MatchOne),
+								Values: []string{
+									"websocket",
+								},
+								Transforms: []string{"lowercase"},
+							},
+						},
+						Then: []SingleHeader{
+							{
+								Name:      "Sec-WebSocket-Protocol",
+								MatchType: string(MatchOne),
+								Values: []string{
+									"graphql-ws",
+									"mqtt",
+								},
+								Source: SourceList,
+							},
+						},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "WebSocketRule_Success",
+					headers: map[string]string{
+						"Upgrade":                "WebSocket",
+						"Sec-WebSocket-Protocol": "chat, mqtt",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name:           "WebSocketRule_Success_NoUpgrade",
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "WebSocketRule_Fail_Protocol",
+					headers: map[string]string{
+						"Upgrade":                "websocket",
+						"Sec-WebSocket-Protocol": "chat",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// RuleWithoutCondition
+		{
+			config: &Config{
+				Rules: []ConditionalRule{
+					{
+						Then: []SingleHeader{
+							{
+								Name:   "X-Partner-Id",
+								Format: "uuid",
+							},
+						},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "RuleWithoutCondition",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: rules[0].if: missing headers"),
+				},
+			},
+		},
+		// RuleWithoutBranch
+		{
+			config: &Config{
+				Rules: []ConditionalRule{
+					{
+						If: []SingleHeader{
+							{
+								Name:   "X-Partner-Id",
+								Format: "uuid",
+							},
+						},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "RuleWithoutBranch",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: rules[0]: missing 'then' or 'else' headers"),
+				},
+			},
+		},
+		// RuleWithInvalidHeader
+		{
+			config: &Config{
+				Rules: []ConditionalRule{
+					{
+						If: []SingleHeader{
+							{
+								Name:      "Upgrade",
+								MatchType: string(MatchOne),
+								Values:    []string{"websocket"},
+							},
+						},
+						Then: []SingleHeader{
+							{
+								Name:      "Sec-WebSocket-Protocol",
+								MatchType: string(MatchAll),
+								Values:    []string{"mqtt"},
+							},
+						},
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "RuleWithInvalidHeader",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: rules[0].then[0].matchtype: match-all can only be used in combination with 'contains', 'regex', 'glob', 'prefix', 'suffix', 'mediatype' or 'language'"),
+				},
+			},
+		},
+		//RuleConditionFailureConfig
+		{
+			config: &Config{
+				NameResolution: NameResolutionRejectAmbiguous,
+				Rules: []ConditionalRule{
+					{
+						If: []SingleHeader{
+							{
+								Name:          "X-Client-Type",
+								MatchType:     string(MatchOne),
+								Values:        []string{"partner"},
+								URLDecodeMode: URLDecodeStrict,
+							},
+						},
+						Then: []SingleHeader{
+							{
+								Name:   "X-Partner-Id",
+								Format: "uuid",
+							},
+						},
+						Else: []SingleHeader{
+							{
+								Name:      "X-Partner-Id",
+								MatchType: string(MatchNone),
+								Values:    []string{"*"},
+								Glob:      Bool(true),
+								Required:  Bool(false),
+							},
+						},
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "RuleConditionFailure_Success_Else",
+					headers: map[string]string{
+						"X-Client-Type": "public",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "RuleConditionFailure_Fail_Ambiguous",
+					headers: map[string]string{
+						"X-Client-Type": "partner",
+						"X_Client_Type": "public",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Client-Type: ambiguous",
+				},
+				{
+					name: "RuleConditionFailure_Fail_Malformed",
+					headers: map[string]string{
+						"X-Client-Type": "%zz",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Client-Type: malformed",
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
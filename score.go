@@ -0,0 +1,71 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// defaultWeight is the weight of a header without a configured weight in the 'score' match type.
+const defaultWeight = 1
+
+// validateThreshold checks the threshold and score header of the plugin.
+func validateThreshold(config *Config) error {
+	if config.MatchType != string(MatchScore) && (config.Threshold != 0 || config.ScoreHeader != "") {
+		return fmt.Errorf("threshold and scoreheader can only be used in combination with match type %s", MatchScore)
+	}
+
+	if config.Threshold < 0 {
+		return fmt.Errorf("threshold must not be negative")
+	}
+
+	return nil
+}
+
+// validateWeight checks the weight of a header for the plugin match type.
+func validateWeight(matchType string, vHeader *SingleHeader) error {
+	if matchType != string(MatchScore) && vHeader.Weight != 0 {
+		return fmt.Errorf("weight can only be used in combination with match type %s", MatchScore)
+	}
+
+	if vHeader.Weight < 0 {
+		return fmt.Errorf("weight must not be negative")
+	}
+
+	return nil
+}
+
+// checkScore adds up the weights of the headers that fail and rejects the request when the score is above
+// the threshold. Every failing header is logged with its weight. Below the threshold the score is added to
+// the request in the score header, when configured.
+func checkScore(headers []SingleHeader, config *Config, req *http.Request) *Failure {
+	score := 0
+
+	for i := range headers {
+		vHeader := &headers[i]
+
+		failure := checkHeader(vHeader, req)
+		if failure == nil {
+			continue
+		}
+
+		weight := vHeader.Weight
+		if weight == 0 {
+			weight = defaultWeight
+		}
+
+		score += weight
+
+		fmt.Println("validate-headers (score):", failure.Error(), "adds", weight)
+	}
+
+	if score > config.Threshold {
+		return &Failure{Reason: ReasonRiskScore, Detail: fmt.Sprintf("score %d above threshold %d", score, config.Threshold)}
+	}
+
+	if config.ScoreHeader != "" {
+		req.Header.Set(config.ScoreHeader, strconv.Itoa(score))
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func scoreConfig() *Config {
+	return &Config{
+		MatchType:   string(MatchScore),
+		Threshold:   3,
+		ScoreHeader: "X-Risk-Score",
+		Headers: []SingleHeader{
+			{
+				Name:      "Accept-Language",
+				MatchType: string(MatchOne),
+				Values: []string{
+					"*",
+				},
+				Glob:   Bool(true),
+				Weight: 1,
+			},
+			{
+				Name:      "User-Agent",
+				MatchType: string(MatchNone),
+				Values: []string{
+					"curl/",
+					"python-requests/",
+				},
+				Prefix: Bool(true),
+				Weight: 3,
+			},
+			{
+				NamePattern: "Sec-Fetch-*",
+				MinCount:    1,
+				Weight:      2,
+			},
+		},
+		Error: ErrorConfig{
+			ReasonHeader: "X-Validation-Reason",
+		},
+	}
+}
+
+func TestScore(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//ScoreConfig
+		{
+			config: scoreConfig(),
+			tests: []Test{
+				{
+					name: "Score_Success_Clean",
+					headers: map[string]string{
+						"Accept-Language": "en",
+						"User-Agent":      "Mozilla/5.0",
+						"Sec-Fetch-Mode":  "navigate",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Score_Success_BelowThreshold",
+					headers: map[string]string{
+						"User-Agent": "Mozilla/5.0",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Score_Fail_AboveThreshold",
+					headers: map[string]string{
+						"Accept-Language": "en",
+						"User-Agent":      "curl/8.0",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "risk-score",
+				},
+			},
+		},
+		// NegativeWeight
+		{
+			config: &Config{
+				MatchType: string(MatchScore),
+				Headers: []SingleHeader{
+					{
+						Name:      "User-Agent",
+						MatchType: string(MatchOne),
+						Values:    []string{"Mozilla/"},
+						Prefix:    Bool(true),
+						Weight:    -1,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "NegativeWeight",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].weight: weight must not be negative"),
+				},
+			},
+		},
+		// WeightWithoutScore
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "User-Agent",
+						MatchType: string(MatchOne),
+						Values:    []string{"Mozilla/"},
+						Prefix:    Bool(true),
+						Weight:    2,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "WeightWithoutScore",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].weight: weight can only be used in combination with match type score"),
+				},
+			},
+		},
+		// ThresholdWithoutScore
+		{
+			config: &Config{
+				Threshold: 2,
+				Headers: []SingleHeader{
+					{
+						Name:      "User-Agent",
+						MatchType: string(MatchOne),
+						Values:    []string{"Mozilla/"},
+						Prefix:    Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "ThresholdWithoutScore",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: threshold: threshold and scoreheader can only be used in combination with match type score"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
+
+func TestScoreHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name: "Clean",
+			headers: map[string]string{
+				"Accept-Language": "en",
+				"User-Agent":      "Mozilla/5.0",
+				"Sec-Fetch-Site":  "same-origin",
+				"X-Risk-Score":    "0",
+			},
+			expected: "0",
+		},
+		{
+			name: "MissingSoftSignals",
+			headers: map[string]string{
+				"User-Agent":   "Mozilla/5.0",
+				"X-Risk-Score": "-100",
+			},
+			expected: "3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var forwarded string
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req.Header.Get("X-Risk-Score")
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			h, err := New(nil, next, scoreConfig(), "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("got %d, want %d", rr.Code, http.StatusOK)
+			}
+
+			if forwarded != tt.expected {
+				t.Errorf("got score %q, want %q", forwarded, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,683 @@
+package traefik_plugin_validate_headers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sfKind is the type of a Structured Field bare item (RFC 8941).
+type sfKind int
+
+const (
+	sfInteger sfKind = iota
+	sfDecimal
+	sfString
+	sfToken
+	sfByteSequence
+	sfBoolean
+)
+
+// sfBareItem is a parsed Structured Field bare item.
+type sfBareItem struct {
+	kind    sfKind
+	integer int64
+	decimal float64
+	str     string
+	bytes   []byte
+	boolean bool
+}
+
+// sfParam is a parameter of a Structured Field item or inner list.
+type sfParam struct {
+	key   string
+	value sfBareItem
+}
+
+// sfMember is a Structured Field item or inner list together with its parameters.
+type sfMember struct {
+	item      sfBareItem
+	innerList []sfMember
+	isInner   bool
+	params    []sfParam
+}
+
+// sfDictMember is a named member of a Structured Field dictionary.
+type sfDictMember struct {
+	key    string
+	member sfMember
+}
+
+// sfParser parses Structured Field values as described in RFC 8941, section 4.2.
+type sfParser struct {
+	input string
+	pos   int
+}
+
+// newSFParser creates a parser for a header value, discarding leading and trailing spaces.
+func newSFParser(value string) *sfParser {
+	return &sfParser{input: strings.Trim(value, " ")}
+}
+
+// parseSFItem parses a header value as a Structured Field item.
+func parseSFItem(value string) (sfMember, error) {
+	p := newSFParser(value)
+
+	member, err := p.parseItem()
+	if err != nil {
+		return sfMember{}, err
+	}
+
+	return member, p.expectEnd()
+}
+
+// parseSFList parses a header value as a Structured Field list.
+func parseSFList(value string) ([]sfMember, error) {
+	p := newSFParser(value)
+
+	var members []sfMember
+
+	for !p.eof() {
+		member, err := p.parseItemOrInnerList()
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, member)
+
+		if err := p.parseSeparator(); err != nil {
+			return nil, err
+		}
+	}
+
+	return members, nil
+}
+
+// parseSFDictionary parses a header value as a Structured Field dictionary. Duplicate keys keep their
+// first position and their last value.
+func parseSFDictionary(value string) ([]sfDictMember, error) {
+	p := newSFParser(value)
+
+	var members []sfDictMember
+
+	for !p.eof() {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		var member sfMember
+
+		if p.peek() == '=' {
+			p.pos++
+
+			member, err = p.parseItemOrInnerList()
+		} else {
+			member.item = sfBareItem{kind: sfBoolean, boolean: true}
+			member.params, err = p.parseParameters()
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		replaced := false
+		for i := range members {
+			if members[i].key == key {
+				members[i].member = member
+				replaced = true
+			}
+		}
+
+		if !replaced {
+			members = append(members, sfDictMember{key: key, member: member})
+		}
+
+		if err := p.parseSeparator(); err != nil {
+			return nil, err
+		}
+	}
+
+	return members, nil
+}
+
+func (p *sfParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *sfParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *sfParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid structured field at position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *sfParser) expectEnd() error {
+	if !p.eof() {
+		return p.errorf("unexpected character %q", p.peek())
+	}
+
+	return nil
+}
+
+// parseSeparator consumes the comma between list or dictionary members.
+func (p *sfParser) parseSeparator() error {
+	p.skipOWS()
+
+	if p.eof() {
+		return nil
+	}
+
+	if p.peek() != ',' {
+		return p.errorf("expected ',' but found %q", p.peek())
+	}
+
+	p.pos++
+	p.skipOWS()
+
+	if p.eof() {
+		return p.errorf("trailing comma")
+	}
+
+	return nil
+}
+
+func (p *sfParser) skipSP() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+func (p *sfParser) skipOWS() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+func (p *sfParser) parseItemOrInnerList() (sfMember, error) {
+	if p.peek() == '(' {
+		return p.parseInnerList()
+	}
+
+	return p.parseItem()
+}
+
+func (p *sfParser) parseInnerList() (sfMember, error) {
+	p.pos++
+
+	member := sfMember{isInner: true}
+
+	for !p.eof() {
+		p.skipSP()
+
+		if p.peek() == ')' {
+			p.pos++
+
+			params, err := p.parseParameters()
+			member.params = params
+
+			return member, err
+		}
+
+		item, err := p.parseItem()
+		if err != nil {
+			return sfMember{}, err
+		}
+
+		member.innerList = append(member.innerList, item)
+
+		if c := p.peek(); c != ' ' && c != ')' {
+			return sfMember{}, p.errorf("expected ' ' or ')' in inner list")
+		}
+	}
+
+	return sfMember{}, p.errorf("unterminated inner list")
+}
+
+func (p *sfParser) parseItem() (sfMember, error) {
+	item, err := p.parseBareItem()
+	if err != nil {
+		return sfMember{}, err
+	}
+
+	params, err := p.parseParameters()
+
+	return sfMember{item: item, params: params}, err
+}
+
+func (p *sfParser) parseParameters() ([]sfParam, error) {
+	var params []sfParam
+
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSP()
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		value := sfBareItem{kind: sfBoolean, boolean: true}
+
+		if p.peek() == '=' {
+			p.pos++
+
+			if value, err = p.parseBareItem(); err != nil {
+				return nil, err
+			}
+		}
+
+		replaced := false
+		for i := range params {
+			if params[i].key == key {
+				params[i].value = value
+				replaced = true
+			}
+		}
+
+		if !replaced {
+			params = append(params, sfParam{key: key, value: value})
+		}
+	}
+
+	return params, nil
+}
+
+func (p *sfParser) parseKey() (string, error) {
+	c := p.peek()
+	if !(c >= 'a' && c <= 'z') && c != '*' {
+		return "", p.errorf("invalid key")
+	}
+
+	start := p.pos
+	for !p.eof() {
+		c = p.peek()
+		if !((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' || c == '-' || c == '.' || c == '*') {
+			break
+		}
+
+		p.pos++
+	}
+
+	return p.input[start:p.pos], nil
+}
+
+func (p *sfParser) parseBareItem() (sfBareItem, error) {
+	c := p.peek()
+
+	switch {
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case c == '"':
+		return p.parseString()
+	case c == '*' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return p.parseToken()
+	case c == ':':
+		return p.parseByteSequence()
+	case c == '?':
+		return p.parseBoolean()
+	default:
+		return sfBareItem{}, p.errorf("unexpected character %q", c)
+	}
+}
+
+func (p *sfParser) parseNumber() (sfBareItem, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	digitsStart := p.pos
+	dot := -1
+
+	for !p.eof() {
+		c := p.peek()
+		if c == '.' && dot < 0 {
+			dot = p.pos
+		} else if c < '0' || c > '9' {
+			break
+		}
+
+		p.pos++
+	}
+
+	number := p.input[start:p.pos]
+	digits := p.input[digitsStart:p.pos]
+
+	if digits == "" || digits[0] == '.' {
+		return sfBareItem{}, p.errorf("invalid number %q", number)
+	}
+
+	if dot < 0 {
+		if len(digits) > 15 {
+			return sfBareItem{}, p.errorf("integer %q has more than 15 digits", number)
+		}
+
+		integer, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return sfBareItem{}, p.errorf("invalid integer %q", number)
+		}
+
+		return sfBareItem{kind: sfInteger, integer: integer}, nil
+	}
+
+	intPart, fracPart := dot-digitsStart, p.pos-dot-1
+	if intPart > 12 || fracPart < 1 || fracPart > 3 {
+		return sfBareItem{}, p.errorf("invalid decimal %q", number)
+	}
+
+	decimal, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return sfBareItem{}, p.errorf("invalid decimal %q", number)
+	}
+
+	return sfBareItem{kind: sfDecimal, decimal: decimal}, nil
+}
+
+func (p *sfParser) parseString() (sfBareItem, error) {
+	p.pos++
+
+	var sb strings.Builder
+
+	for !p.eof() {
+		c := p.input[p.pos]
+		p.pos++
+
+		switch {
+		case c == '\\':
+			if p.eof() || (p.peek() != '"' && p.peek() != '\\') {
+				return sfBareItem{}, p.errorf("invalid escape in string")
+			}
+
+			sb.WriteByte(p.input[p.pos])
+			p.pos++
+		case c == '"':
+			return sfBareItem{kind: sfString, str: sb.String()}, nil
+		case c < 0x20 || c > 0x7e:
+			return sfBareItem{}, p.errorf("invalid character in string")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sfBareItem{}, p.errorf("unterminated string")
+}
+
+func (p *sfParser) parseToken() (sfBareItem, error) {
+	start := p.pos
+	p.pos++
+
+	for !p.eof() {
+		c := rune(p.peek())
+		if !isTokenChar(c) && c != ':' && c != '/' {
+			break
+		}
+
+		p.pos++
+	}
+
+	return sfBareItem{kind: sfToken, str: p.input[start:p.pos]}, nil
+}
+
+func (p *sfParser) parseByteSequence() (sfBareItem, error) {
+	p.pos++
+
+	end := strings.IndexByte(p.input[p.pos:], ':')
+	if end < 0 {
+		return sfBareItem{}, p.errorf("unterminated byte sequence")
+	}
+
+	encoded := p.input[p.pos : p.pos+end]
+	p.pos += end + 1
+
+	decoded, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return sfBareItem{}, p.errorf("invalid base64 in byte sequence")
+	}
+
+	return sfBareItem{kind: sfByteSequence, bytes: decoded}, nil
+}
+
+func (p *sfParser) parseBoolean() (sfBareItem, error) {
+	p.pos++
+
+	switch p.peek() {
+	case '1':
+		p.pos++
+		return sfBareItem{kind: sfBoolean, boolean: true}, nil
+	case '0':
+		p.pos++
+		return sfBareItem{kind: sfBoolean, boolean: false}, nil
+	default:
+		return sfBareItem{}, p.errorf("invalid boolean")
+	}
+}
+
+// String serializes the bare item in its canonical form, e.g. `token`, `"string"`, `42`, `1.5`, `?1` or `:aGk=:`.
+func (b sfBareItem) String() string {
+	switch b.kind {
+	case sfInteger:
+		return strconv.FormatInt(b.integer, 10)
+	case sfDecimal:
+		decimal := strconv.FormatFloat(math.Round(b.decimal*1000)/1000, 'f', -1, 64)
+		if !strings.Contains(decimal, ".") {
+			decimal += ".0"
+		}
+
+		return decimal
+	case sfString:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(b.str) + `"`
+	case sfToken:
+		return b.str
+	case sfByteSequence:
+		return ":" + base64.StdEncoding.EncodeToString(b.bytes) + ":"
+	default:
+		if b.boolean {
+			return "?1"
+		}
+
+		return "?0"
+	}
+}
+
+// String serializes the member including its parameters.
+func (m sfMember) String() string {
+	var sb strings.Builder
+
+	if m.isInner {
+		sb.WriteString("(")
+
+		for i, item := range m.innerList {
+			if i > 0 {
+				sb.WriteString(" ")
+			}
+
+			sb.WriteString(item.String())
+		}
+
+		sb.WriteString(")")
+	} else {
+		sb.WriteString(m.item.String())
+	}
+
+	for _, param := range m.params {
+		sb.WriteString(";" + param.key)
+
+		if param.value.kind != sfBoolean || !param.value.boolean {
+			sb.WriteString("=" + param.value.String())
+		}
+	}
+
+	return sb.String()
+}
+
+// canonicalSFItem parses a configured value as a bare item and returns its canonical serialization.
+func canonicalSFItem(value string) (string, error) {
+	p := newSFParser(value)
+
+	item, err := p.parseBareItem()
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.expectEnd(); err != nil {
+		return "", err
+	}
+
+	return item.String(), nil
+}
+
+// sfSelector selects values from a parsed Structured Field, e.g. `key`, `[0]`, `key[1]` or `key;param`.
+type sfSelector struct {
+	key      string
+	indexes  []int
+	param    string
+	hasParam bool
+}
+
+// compileSFSelector parses a selector for the given Structured Field source. Keys can only be selected
+// from a dictionary and indexes only from a list or dictionary.
+func compileSFSelector(path string, source string) (*sfSelector, error) {
+	p := newSFParser(path)
+	selector := &sfSelector{}
+
+	if c := p.peek(); (c >= 'a' && c <= 'z') || c == '*' {
+		if source != SourceSFDictionary {
+			return nil, fmt.Errorf("invalid path %q, keys can only be selected from a dictionary", path)
+		}
+
+		selector.key, _ = p.parseKey()
+	}
+
+	if p.peek() == '[' && source == SourceSFItem {
+		return nil, fmt.Errorf("invalid path %q, an item has no members", path)
+	}
+
+	for p.peek() == '[' {
+		end := strings.IndexByte(p.input[p.pos:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid path %q, unterminated bracket", path)
+		}
+
+		index, err := strconv.Atoi(p.input[p.pos+1 : p.pos+end])
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid path %q, invalid index", path)
+		}
+
+		selector.indexes = append(selector.indexes, index)
+		p.pos += end + 1
+	}
+
+	if p.peek() == ';' {
+		p.pos++
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q, invalid parameter name", path)
+		}
+
+		selector.param = key
+		selector.hasParam = true
+	}
+
+	if !p.eof() {
+		return nil, fmt.Errorf("invalid path %q, unexpected character %q", path, p.peek())
+	}
+
+	return selector, nil
+}
+
+// selectValues parses the header value as the given Structured Field type and returns the canonical
+// serialization of the selected bare items. Inner lists are flattened into their items. Without a
+// selector a dictionary returns its keys.
+func (s *sfSelector) selectValues(value string, source string) ([]string, error) {
+	var members []sfMember
+
+	switch source {
+	case SourceSFItem:
+		item, err := parseSFItem(value)
+		if err != nil {
+			return nil, err
+		}
+
+		members = []sfMember{item}
+	case SourceSFList:
+		list, err := parseSFList(value)
+		if err != nil {
+			return nil, err
+		}
+
+		members = list
+	default:
+		dict, err := parseSFDictionary(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.key == "" && len(s.indexes) == 0 && !s.hasParam {
+			keys := make([]string, 0, len(dict))
+			for _, member := range dict {
+				keys = append(keys, member.key)
+			}
+
+			return keys, nil
+		}
+
+		for _, member := range dict {
+			if s.key == "" || member.key == s.key {
+				members = append(members, member.member)
+			}
+		}
+	}
+
+	indexes := s.indexes
+
+	if source == SourceSFList && len(indexes) > 0 {
+		if indexes[0] >= len(members) {
+			return nil, nil
+		}
+
+		members = []sfMember{members[indexes[0]]}
+		indexes = indexes[1:]
+	}
+
+	for _, index := range indexes {
+		var selected []sfMember
+
+		for _, member := range members {
+			if member.isInner && index < len(member.innerList) {
+				selected = append(selected, member.innerList[index])
+			}
+		}
+
+		members = selected
+	}
+
+	var values []string
+
+	for _, member := range members {
+		switch {
+		case s.hasParam:
+			for _, param := range member.params {
+				if param.key == s.param {
+					values = append(values, param.value.String())
+				}
+			}
+		case member.isInner:
+			for _, item := range member.innerList {
+				values = append(values, item.item.String())
+			}
+		default:
+			values = append(values, member.item.String())
+		}
+	}
+
+	return values, nil
+}
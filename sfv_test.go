@@ -0,0 +1,318 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestParseSF(t *testing.T) {
+	tests := []struct {
+		source    string
+		value     string
+		expected  string
+		expectErr bool
+	}{
+		{source: SourceSFItem, value: "42", expected: "[42]"},
+		{source: SourceSFItem, value: "-0042", expected: "[-42]"},
+		{source: SourceSFItem, value: "1.50", expected: "[1.5]"},
+		{source: SourceSFItem, value: "2.0", expected: "[2.0]"},
+		{source: SourceSFItem, value: `"a \"b\" \\c"`, expected: `["a \"b\" \\c"]`},
+		{source: SourceSFItem, value: "text/html;q=0.5", expected: "[text/html;q=0.5]"},
+		{source: SourceSFItem, value: ":aGk=:", expected: "[:aGk=:]"},
+		{source: SourceSFItem, value: "?0;a;b=?1", expected: "[?0;a;b]"},
+		{source: SourceSFItem, value: "1234567890123456", expectErr: true},
+		{source: SourceSFItem, value: "1.2345", expectErr: true},
+		{source: SourceSFItem, value: "1.", expectErr: true},
+		{source: SourceSFItem, value: `"unterminated`, expectErr: true},
+		{source: SourceSFItem, value: `"bad \n escape"`, expectErr: true},
+		{source: SourceSFItem, value: "?2", expectErr: true},
+		{source: SourceSFItem, value: ":!!:", expectErr: true},
+		{source: SourceSFItem, value: "a b", expectErr: true},
+		{source: SourceSFList, value: "sugar, tea;q=1,  (rum cola);x", expected: "[sugar tea;q=1 (rum cola);x]"},
+		{source: SourceSFList, value: "", expected: "[]"},
+		{source: SourceSFList, value: "a,", expectErr: true},
+		{source: SourceSFList, value: "(a b", expectErr: true},
+		{source: SourceSFDictionary, value: "u=1, i, a=2, u=3", expected: "[u=3 i=?1 a=2]"},
+		{source: SourceSFDictionary, value: "sig1=(\"@method\" \"@path\");alg=\"ed25519\"", expected: `[sig1=("@method" "@path");alg="ed25519"]`},
+		{source: SourceSFDictionary, value: "U=1", expectErr: true},
+		{source: SourceSFDictionary, value: "a=1 b=2", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"_"+tt.value, func(t *testing.T) {
+			var actual []string
+			var err error
+
+			switch tt.source {
+			case SourceSFItem:
+				var member sfMember
+				if member, err = parseSFItem(tt.value); err == nil {
+					actual = []string{member.String()}
+				}
+			case SourceSFList:
+				var members []sfMember
+				if members, err = parseSFList(tt.value); err == nil {
+					for _, member := range members {
+						actual = append(actual, member.String())
+					}
+				}
+			default:
+				var members []sfDictMember
+				if members, err = parseSFDictionary(tt.value); err == nil {
+					for _, member := range members {
+						actual = append(actual, member.key+"="+member.member.String())
+					}
+				}
+			}
+
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", actual)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(actual) != tt.expected {
+				t.Errorf("got %s, want %s", fmt.Sprint(actual), tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractSFValues(t *testing.T) {
+	tests := []struct {
+		source    string
+		path      string
+		value     string
+		expected  []string
+		expectErr bool
+	}{
+		{source: SourceSFItem, value: "abc;q=1", expected: []string{"abc"}},
+		{source: SourceSFItem, path: ";q", value: "abc;q=1", expected: []string{"1"}},
+		{source: SourceSFItem, path: ";x", value: "abc;q=1", expected: nil},
+		{source: SourceSFList, value: "a, (b c), d", expected: []string{"a", "b", "c", "d"}},
+		{source: SourceSFList, path: "[1][0]", value: "a, (b c), d", expected: []string{"b"}},
+		{source: SourceSFList, path: "[5]", value: "a, b", expected: nil},
+		{source: SourceSFList, path: ";q", value: "a;q=0.5, b, c;q=1", expected: []string{"0.5", "1"}},
+		{source: SourceSFDictionary, value: "u=3, i", expected: []string{"u", "i"}},
+		{source: SourceSFDictionary, path: "u", value: "u=3, i", expected: []string{"3"}},
+		{source: SourceSFDictionary, path: "i", value: "u=3, i", expected: []string{"?1"}},
+		{source: SourceSFDictionary, path: "x", value: "u=3, i", expected: nil},
+		{source: SourceSFDictionary, path: "sig1;alg", value: `sig1=("@method");alg="ed25519"`, expected: []string{`"ed25519"`}},
+		{source: SourceSFDictionary, path: "sig1", value: `sig1=("@method" "@path")`, expected: []string{`"@method"`, `"@path"`}},
+		{source: SourceSFDictionary, path: "u", value: "u=", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"_"+tt.path+"_"+tt.value, func(t *testing.T) {
+			vHeader := &SingleHeader{Source: tt.source, Path: tt.path}
+			if err := compileSource(vHeader); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := extractValues(tt.value, vHeader)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", actual)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(actual) != fmt.Sprint(tt.expected) || (actual == nil) != (tt.expected == nil) {
+				t.Errorf("got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompileSFSelectorInvalid(t *testing.T) {
+	tests := []struct {
+		source string
+		path   string
+	}{
+		{source: SourceSFList, path: "key"},
+		{source: SourceSFItem, path: "[0]"},
+		{source: SourceSFDictionary, path: "key[x]"},
+		{source: SourceSFDictionary, path: "key[0"},
+		{source: SourceSFDictionary, path: "key;"},
+		{source: SourceSFDictionary, path: "Key"},
+	}
+
+	for _, tt := range tests {
+		if _, err := compileSFSelector(tt.path, tt.source); err == nil {
+			t.Errorf("expected an error for path %q of source %s", tt.path, tt.source)
+		}
+	}
+}
+
+func TestStructuredField(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//SFPriorityConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Priority",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"0",
+							"01",
+							"2",
+						},
+						Source: SourceSFDictionary,
+						Path:   "u",
+					},
+					{
+						Name:      "Priority",
+						MatchType: string(MatchNone),
+						Values: []string{
+							"?1",
+						},
+						Source:   SourceSFDictionary,
+						Path:     "i",
+						Required: Bool(false),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "SFPriority_Success",
+					headers: map[string]string{
+						"Priority": "u=1",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "SFPriority_Fail_Urgency",
+					headers: map[string]string{
+						"Priority": "u=5",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Priority: mismatch",
+				},
+				{
+					name: "SFPriority_Fail_TypeMismatch",
+					headers: map[string]string{
+						"Priority": `u="1"`,
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Priority: mismatch",
+				},
+				{
+					name: "SFPriority_Fail_Incremental",
+					headers: map[string]string{
+						"Priority": "u=2, i",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Priority: mismatch",
+				},
+				{
+					name: "SFPriority_Fail_Malformed",
+					headers: map[string]string{
+						"Priority": "u=1;",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Priority: malformed",
+				},
+				{
+					name: "SFPriority_Fail_KeyMissing",
+					headers: map[string]string{
+						"Priority": "i",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Priority: missing",
+				},
+			},
+		},
+		//SFListConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Cache-Status",
+						MatchType: string(MatchNone),
+						Values: []string{
+							"stale",
+						},
+						Source: SourceSFList,
+						Path:   ";fwd",
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "SFList_Success",
+					headers: map[string]string{
+						"Cache-Status": "ExampleCache; hit, CDN; fwd=uri-miss",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "SFList_Fail_Blacklisted",
+					headers: map[string]string{
+						"Cache-Status": "ExampleCache; hit, CDN; fwd=stale",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// InvalidSFValue
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Priority",
+						MatchType: string(MatchOne),
+						Values:    []string{"u=1"},
+						Source:    SourceSFDictionary,
+						Path:      "u",
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "InvalidSFValue",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].source: value \"u=1\" is not a structured field item: invalid structured field at position 1: unexpected character '='"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
+
+func TestStructuredFieldValuesUnchanged(t *testing.T) {
+	config := &Config{
+		Headers: []SingleHeader{
+			{
+				Name:      "Priority",
+				MatchType: string(MatchOne),
+				Values:    []string{"01", "?1"},
+				Source:    SourceSFDictionary,
+				Path:      "u",
+			},
+		},
+	}
+
+	if _, err := New(nil, http.HandlerFunc(dummyHandler), config, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if values := config.Headers[0].Values; values[0] != "01" || values[1] != "?1" {
+		t.Errorf("got values %q, want the configured values", values)
+	}
+}
@@ -0,0 +1,162 @@
+package traefik_plugin_validate_headers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SourceJSON parses the header value as JSON and selects the field at the configured path.
+	SourceJSON = "json"
+	// SourceBase64JSON decodes the header value from base64 before parsing it as JSON.
+	SourceBase64JSON = "base64json"
+	// SourceList splits the header value into its comma-separated elements.
+	SourceList = "list"
+	// SourceSFItem parses the header value as a Structured Field item (RFC 8941).
+	SourceSFItem = "sfv-item"
+	// SourceSFList parses the header value as a Structured Field list (RFC 8941).
+	SourceSFList = "sfv-list"
+	// SourceSFDictionary parses the header value as a Structured Field dictionary (RFC 8941).
+	SourceSFDictionary = "sfv-dictionary"
+	// SourceWeightedList parses the header value as a list of elements with q-values, e.g. `de-DE,de;q=0.9`.
+	SourceWeightedList = "weighted-list"
+)
+
+// compileSource validates the source configuration of a header and compiles its path.
+func compileSource(vHeader *SingleHeader) error {
+	if err := validatePreference(vHeader); err != nil {
+		return err
+	}
+
+	switch vHeader.Source {
+	case "", SourceList, SourceWeightedList:
+		if vHeader.Path != "" {
+			return fmt.Errorf("path can only be used in combination with a json or sfv source")
+		}
+
+		return nil
+	case SourceJSON, SourceBase64JSON:
+		path, err := compileJSONPath(vHeader.Path)
+		if err != nil {
+			return err
+		}
+
+		vHeader.jsonPath = path
+
+		return nil
+	case SourceSFItem, SourceSFList, SourceSFDictionary:
+		selector, err := compileSFSelector(vHeader.Path, vHeader.Source)
+		if err != nil {
+			return err
+		}
+
+		vHeader.sfSelector = selector
+
+		// Exactly matched values are compared with the canonical serialization of the selected items.
+		if len(vHeader.matchModes()) > 0 {
+			return nil
+		}
+
+		sfValues := make([]string, len(vHeader.Values))
+
+		for i, value := range vHeader.Values {
+			if strings.TrimSpace(value) == "" {
+				sfValues[i] = value
+				continue
+			}
+
+			canonical, err := canonicalSFItem(value)
+			if err != nil {
+				return fmt.Errorf("value %q is not a structured field item: %w", value, err)
+			}
+
+			sfValues[i] = canonical
+		}
+
+		vHeader.sfValues = sfValues
+
+		return nil
+	default:
+		return fmt.Errorf("unknown source %q, allowed: %s, %s, %s, %s, %s, %s, %s", vHeader.Source, SourceJSON, SourceBase64JSON, SourceList, SourceSFItem, SourceSFList, SourceSFDictionary, SourceWeightedList)
+	}
+}
+
+// extractValues extracts the values to match from the header value according to the configured source.
+// A path that doesn't exist results in no values, so the header is handled as missing.
+func extractValues(value string, vHeader *SingleHeader) ([]string, error) {
+	switch vHeader.Source {
+	case SourceList:
+		return splitList(value), nil
+	case SourceWeightedList:
+		return weightedValues(value, vHeader.Preference)
+	case SourceSFItem, SourceSFList, SourceSFDictionary:
+		return vHeader.sfSelector.selectValues(value, vHeader.Source)
+	}
+
+	if vHeader.Source == SourceBase64JSON {
+		decoded, err := decodeBase64(value)
+		if err != nil {
+			return nil, err
+		}
+
+		value = decoded
+	}
+
+	node, err := vHeader.jsonPath.selectJSON(value)
+	if errors.Is(err, errJSONPathNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	elements, isArray := node.([]interface{})
+	if !isArray {
+		elements = []interface{}{node}
+	}
+
+	values := make([]string, 0, len(elements))
+
+	for _, element := range elements {
+		str, err := jsonScalarString(element)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, str)
+	}
+
+	return values, nil
+}
+
+// splitList splits a comma-separated header value into its trimmed, non-empty elements.
+func splitList(value string) []string {
+	var values []string
+
+	for _, element := range strings.Split(value, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			values = append(values, element)
+		}
+	}
+
+	return values
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without padding.
+func decodeBase64(value string) (string, error) {
+	trimmed := strings.TrimRight(value, "=")
+
+	if decoded, err := base64.RawStdEncoding.DecodeString(trimmed); err == nil {
+		return string(decoded), nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(trimmed)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64: %w", err)
+	}
+
+	return string(decoded), nil
+}
@@ -0,0 +1,240 @@
+package traefik_plugin_validate_headers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestExtractValues(t *testing.T) {
+	tests := []struct {
+		source    string
+		path      string
+		value     string
+		expected  []string
+		expectErr bool
+	}{
+		{source: SourceJSON, path: "$.email_verified", value: `{"email_verified":true}`, expected: []string{"true"}},
+		{source: SourceJSON, path: "$.groups", value: `{"groups":["admins","users"]}`, expected: []string{"admins", "users"}},
+		{source: SourceJSON, path: "$.groups[1]", value: `{"groups":["admins","users"]}`, expected: []string{"users"}},
+		{source: SourceJSON, path: "$.groups", value: `{"groups":[]}`, expected: []string{}},
+		{source: SourceJSON, path: "$.missing", value: `{"groups":[]}`, expected: nil},
+		{source: SourceJSON, path: "$.a.b", value: `{"a":{"b":1.5}}`, expected: []string{"1.5"}},
+		{source: SourceJSON, path: "$.a", value: `{"a":{"b":1}}`, expected: []string{`{"b":1}`}},
+		{source: SourceJSON, path: "$", value: `"plain"`, expected: []string{"plain"}},
+		{source: SourceJSON, path: "$.a", value: `{"a":`, expectErr: true},
+		{source: SourceJSON, path: "$.a[0]", value: `{"a":{"b":1}}`, expectErr: true},
+		{source: SourceBase64JSON, path: "$.sub", value: base64.StdEncoding.EncodeToString([]byte(`{"sub":"jane"}`)), expected: []string{"jane"}},
+		{source: SourceBase64JSON, path: "$.sub", value: base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"j?>"}`)), expected: []string{"j?>"}},
+		{source: SourceBase64JSON, path: "$.sub", value: "!!!", expectErr: true},
+		{source: SourceList, value: "admins, users,,ops ", expected: []string{"admins", "users", "ops"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"_"+tt.path+"_"+tt.value, func(t *testing.T) {
+			vHeader := &SingleHeader{Source: tt.source, Path: tt.path}
+			if err := compileSource(vHeader); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := extractValues(tt.value, vHeader)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", actual)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(actual) != fmt.Sprint(tt.expected) || (actual == nil) != (tt.expected == nil) {
+				t.Errorf("got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	userinfo := func(json string) string {
+		return base64.StdEncoding.EncodeToString([]byte(json))
+	}
+
+	configTestPairs := []TestConfig{
+		//JSONGroupsConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Userinfo",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"admins",
+						},
+						Source: SourceBase64JSON,
+						Path:   "$.groups",
+					},
+					{
+						Name:      "X-Userinfo",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"true",
+						},
+						Source: SourceBase64JSON,
+						Path:   "$.email_verified",
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "JSONGroups_Success",
+					headers: map[string]string{
+						"X-Userinfo": userinfo(`{"groups":["users","admins"],"email_verified":true}`),
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "JSONGroups_Fail_NotInGroup",
+					headers: map[string]string{
+						"X-Userinfo": userinfo(`{"groups":["users"],"email_verified":true}`),
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Userinfo: mismatch",
+				},
+				{
+					name: "JSONGroups_Fail_NotVerified",
+					headers: map[string]string{
+						"X-Userinfo": userinfo(`{"groups":["admins"],"email_verified":false}`),
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Userinfo: mismatch",
+				},
+				{
+					name: "JSONGroups_Fail_FieldMissing",
+					headers: map[string]string{
+						"X-Userinfo": userinfo(`{"groups":["admins"]}`),
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Userinfo: missing",
+				},
+				{
+					name: "JSONGroups_Fail_Malformed",
+					headers: map[string]string{
+						"X-Userinfo": userinfo(`{"groups":`),
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Userinfo: malformed",
+				},
+			},
+		},
+		//ListNoneConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Auth-Request-Groups",
+						MatchType: string(MatchNone),
+						Values: []string{
+							"banned",
+						},
+						Source: SourceList,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "ListNone_Success",
+					headers: map[string]string{
+						"X-Auth-Request-Groups": "users, admins",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ListNone_Fail",
+					headers: map[string]string{
+						"X-Auth-Request-Groups": "users, banned",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		//ListContainsConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Auth-Request-Groups",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"admin",
+						},
+						Source:   SourceList,
+						Contains: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "ListContains_Success",
+					headers: map[string]string{
+						"X-Auth-Request-Groups": "users, org-admins",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ListContains_Fail",
+					headers: map[string]string{
+						"X-Auth-Request-Groups": "users, ops",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// UnknownSource
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Userinfo",
+						MatchType: string(MatchOne),
+						Values:    []string{"a"},
+						Source:    "xml",
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "UnknownSource",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].source: unknown source \"xml\", allowed: json, base64json, list, sfv-item, sfv-list, sfv-dictionary, weighted-list"),
+				},
+			},
+		},
+		// PathWithoutSource
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Userinfo",
+						MatchType: string(MatchOne),
+						Values:    []string{"a"},
+						Path:      "$.sub",
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "PathWithoutSource",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].source: path can only be used in combination with a json or sfv source"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
@@ -0,0 +1,155 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// templateRegex matches the request attribute placeholders that can be used in values, e.g. `{host}`,
+// `{header:X-Tenant-Id}`, `{query:tenant}`, `{method}`, `{path}` or `{path.segment[0]}`. Other braces,
+// such as regex quantifiers, are kept as they are.
+var templateRegex = regexp.MustCompile(`\{(host|method|path|path\.segment\[(\d+)\]|header:([^{}]+)|query:([^{}]+))\}`)
+
+// templatePart is a literal part of a value or a placeholder that is resolved per request.
+type templatePart struct {
+	literal   string
+	attribute string
+	name      string
+	index     int
+}
+
+// valueTemplate is a configured value that references request attributes.
+type valueTemplate []templatePart
+
+// compileTemplates parses the placeholders of the values; the result is nil when no value uses them.
+func compileTemplates(values []string) []valueTemplate {
+	var templates []valueTemplate
+
+	for i, value := range values {
+		matches := templateRegex.FindAllStringSubmatchIndex(value, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		if templates == nil {
+			templates = make([]valueTemplate, len(values))
+		}
+
+		var template valueTemplate
+
+		last := 0
+		for _, match := range matches {
+			if match[0] > last {
+				template = append(template, templatePart{literal: value[last:match[0]]})
+			}
+
+			part := templatePart{attribute: value[match[2]:match[3]]}
+
+			switch {
+			case match[4] >= 0:
+				part.attribute = "path.segment"
+				part.index, _ = strconv.Atoi(value[match[4]:match[5]])
+			case match[6] >= 0:
+				part.attribute = "header"
+				part.name = value[match[6]:match[7]]
+			case match[8] >= 0:
+				part.attribute = "query"
+				part.name = value[match[8]:match[9]]
+			}
+
+			template = append(template, part)
+			last = match[1]
+		}
+
+		if last < len(value) {
+			template = append(template, templatePart{literal: value[last:]})
+		}
+
+		templates[i] = template
+	}
+
+	return templates
+}
+
+// resolve returns the request attribute of the placeholder; empty when the attribute is not present or,
+// for headers, ambiguous according to the name resolution policy.
+func (p templatePart) resolve(req *http.Request, nameResolution string) string {
+	switch p.attribute {
+	case "host":
+		return req.Host
+	case "method":
+		return req.Method
+	case "path":
+		return req.URL.Path
+	case "path.segment":
+		segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		if p.index < len(segments) {
+			return segments[p.index]
+		}
+
+		return ""
+	case "header":
+		value, failure := headerValue(req, p.name, nameResolution)
+		if failure != nil {
+			return ""
+		}
+
+		return value
+	case "query":
+		return req.URL.Query().Get(p.name)
+	default:
+		return p.literal
+	}
+}
+
+// resolveValues returns the header with its templated values resolved for the request. Regex values
+// get the resolved attributes quoted. A placeholder that can't be resolved fails the rule.
+func resolveValues(req *http.Request, vHeader *SingleHeader) (*SingleHeader, *Failure) {
+	if vHeader.templates == nil {
+		return vHeader, nil
+	}
+
+	resolved := *vHeader
+	resolved.Values = make([]string, len(vHeader.Values))
+	// The resolved regex values are compiled when matching.
+	resolved.regexes = nil
+
+	for i, value := range vHeader.Values {
+		template := vHeader.templates[i]
+		if template == nil {
+			resolved.Values[i] = value
+			continue
+		}
+
+		var sb strings.Builder
+
+		for _, part := range template {
+			if part.attribute == "" {
+				sb.WriteString(part.literal)
+				continue
+			}
+
+			attribute := part.resolve(req, vHeader.nameResolution)
+			if attribute == "" {
+				if vHeader.IsDebug() {
+					fmt.Println("validate-headers (debug): Unresolved template:", value)
+				}
+
+				return nil, &Failure{Header: vHeader.Name, Reason: ReasonMismatch, Detail: fmt.Sprintf("unresolved template %q", value)}
+			}
+
+			if vHeader.IsRegex() {
+				attribute = regexp.QuoteMeta(attribute)
+			}
+
+			sb.WriteString(attribute)
+		}
+
+		resolved.Values[i] = sb.String()
+	}
+
+	return &resolved, nil
+}
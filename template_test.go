@@ -0,0 +1,229 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestResolveValues(t *testing.T) {
+	tests := []struct {
+		value     string
+		regex     bool
+		expected  string
+		expectErr bool
+	}{
+		{value: "static", expected: "static"},
+		{value: "^[a-z]{2,3}$", expected: "^[a-z]{2,3}$"},
+		{value: "{host}", expected: "api.example.com:8443"},
+		{value: "https://{host}", expected: "https://api.example.com:8443"},
+		{value: "{method} {path}", expected: "POST /tenants/acme/orders"},
+		{value: "{path.segment[1]}", expected: "acme"},
+		{value: "{path.segment[3]}", expectErr: true},
+		{value: "{query:tenant}", expected: "acme"},
+		{value: "{query:missing}", expectErr: true},
+		{value: "{header:X-Tenant-Id}-{query:region}", expected: "acme-eu"},
+		{value: "{header:X-Missing}", expectErr: true},
+		{value: "^{host}$", regex: true, expected: `^api\.example\.com:8443$`},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com:8443/tenants/acme/orders?tenant=acme&region=eu", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("X-Tenant-Id", "acme")
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			vHeader := &SingleHeader{Name: "X-Test", Values: []string{tt.value}, Regex: Bool(tt.regex)}
+			vHeader.templates = compileTemplates(vHeader.Values)
+
+			resolved, failure := resolveValues(req, vHeader)
+			if tt.expectErr {
+				if failure == nil {
+					t.Errorf("expected a failure, got %q", resolved.Values)
+				}
+
+				return
+			}
+
+			if failure != nil {
+				t.Fatal(failure)
+			}
+
+			if resolved.Values[0] != tt.expected {
+				t.Errorf("got %q, want %q", resolved.Values[0], tt.expected)
+			}
+
+			if vHeader.Values[0] != tt.value {
+				t.Errorf("configured value changed to %q", vHeader.Values[0])
+			}
+		})
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//TenantPathConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Tenant-Id",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"{path.segment[1]}",
+						},
+					},
+					{
+						Name:      "Origin",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"https://{host}",
+						},
+						Required: Bool(false),
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "TenantPath_Success",
+					url:  "https://shop.example.com/tenants/acme/orders",
+					headers: map[string]string{
+						"X-Tenant-Id": "acme",
+						"Origin":      "https://shop.example.com",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "TenantPath_Fail_OtherTenant",
+					url:  "https://shop.example.com/tenants/acme/orders",
+					headers: map[string]string{
+						"X-Tenant-Id": "globex",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Tenant-Id: mismatch",
+				},
+				{
+					name: "TenantPath_Fail_CrossOrigin",
+					url:  "https://shop.example.com/tenants/acme/orders",
+					headers: map[string]string{
+						"X-Tenant-Id": "acme",
+						"Origin":      "https://evil.example.com",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Origin: mismatch",
+				},
+				{
+					name: "TenantPath_Fail_Unresolved",
+					url:  "https://shop.example.com/",
+					headers: map[string]string{
+						"X-Tenant-Id": "acme",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "X-Tenant-Id: mismatch",
+				},
+			},
+		},
+		//ForwardedHostConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Forwarded-Host",
+						MatchType: string(MatchNone),
+						Values: []string{
+							"{header:X-Original-Host}",
+						},
+						Contains: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "ForwardedHost_Success",
+					headers: map[string]string{
+						"X-Forwarded-Host": "a.example.com",
+						"X-Original-Host":  "b.example.com",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "ForwardedHost_Fail_Contains",
+					headers: map[string]string{
+						"X-Forwarded-Host": "a.example.com, b.example.com",
+						"X-Original-Host":  "b.example.com",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "ForwardedHost_Fail_UnresolvedIsClosed",
+					headers: map[string]string{
+						"X-Forwarded-Host": "a.example.com",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		//MethodQueryRegexConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "X-Signature",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"^{method}:{query:id}:[0-9a-f]{8}$",
+						},
+						Regex: Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:   "MethodQueryRegex_Success",
+					method: http.MethodDelete,
+					url:    "/items?id=a.b",
+					headers: map[string]string{
+						"X-Signature": "DELETE:a.b:0badc0de",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name:   "MethodQueryRegex_Fail_QuotedAttribute",
+					method: http.MethodDelete,
+					url:    "/items?id=a.b",
+					headers: map[string]string{
+						"X-Signature": "DELETE:axb:0badc0de",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// TemplateWithGlob
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Origin",
+						MatchType: string(MatchOne),
+						Values:    []string{"https://*.{host}"},
+						Glob:      Bool(true),
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "TemplateWithGlob",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].values: templates can't be used in combination with 'glob' or 'mediatype'"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}
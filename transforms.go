@@ -0,0 +1,214 @@
+package traefik_plugin_validate_headers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// URLDecodeStrict decodes once and fails on invalid escapes; the default.
+	URLDecodeStrict = "strict"
+	// URLDecodeRepeat decodes until the value no longer changes and fails on invalid escapes.
+	URLDecodeRepeat = "repeat"
+	// URLDecodeRejectDouble decodes once and fails on invalid escapes or when the result is still percent-encoded.
+	URLDecodeRejectDouble = "reject-double"
+)
+
+// urlDecodeLenientAlias is the name of the mode that ignored invalid escapes; configurations that still use it
+// decode strictly.
+const urlDecodeLenientAlias = "lenient"
+
+// maxURLDecodeRounds limits the number of decoding rounds in the 'repeat' URL decode mode.
+const maxURLDecodeRounds = 8
+
+// percentEncodedRegex matches a percent-encoded octet.
+var percentEncodedRegex = regexp.MustCompile(`%[0-9A-Fa-f]{2}`)
+
+// transform converts a header value before it is matched.
+type transform func(string) (string, error)
+
+// simpleTransforms maps the names of the transforms without arguments to their implementation.
+var simpleTransforms = map[string]transform{
+	"urldecode":  url.QueryUnescape,
+	"pathdecode": url.PathUnescape,
+	"base64decode": func(value string) (string, error) {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		return string(decoded), err
+	},
+	"base64urldecode": func(value string) (string, error) {
+		decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+		return string(decoded), err
+	},
+	"trim": func(value string) (string, error) {
+		return strings.TrimSpace(value), nil
+	},
+	"lowercase": func(value string) (string, error) {
+		return strings.ToLower(value), nil
+	},
+	"uppercase": func(value string) (string, error) {
+		return strings.ToUpper(value), nil
+	},
+	"rfc2047decode": func(value string) (string, error) {
+		return new(mime.WordDecoder).DecodeHeader(value)
+	},
+}
+
+// compileTransforms parses the configured transforms, e.g. `trim`, `split:,:0` or `jsonpath:$.sub`.
+func compileTransforms(specs []string) ([]transform, error) {
+	transforms := make([]transform, 0, len(specs))
+
+	for _, spec := range specs {
+		t, err := compileTransform(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		transforms = append(transforms, t)
+	}
+
+	return transforms, nil
+}
+
+// compileTransform parses a single transform specification of the form `name` or `name:arguments`.
+func compileTransform(spec string) (transform, error) {
+	name, args, hasArgs := strings.Cut(spec, ":")
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "split":
+		sep, rawIndex, found := cutLast(args, ":")
+		if !hasArgs || !found || sep == "" {
+			return nil, fmt.Errorf("invalid transform %q, expected split:<separator>:<index>", spec)
+		}
+
+		index, err := strconv.Atoi(rawIndex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transform %q, index must be a number", spec)
+		}
+
+		return splitTransform(sep, index), nil
+	case "stripprefix":
+		if !hasArgs || args == "" {
+			return nil, fmt.Errorf("invalid transform %q, expected stripPrefix:<prefix>", spec)
+		}
+
+		return func(value string) (string, error) {
+			return strings.TrimPrefix(value, args), nil
+		}, nil
+	case "jsonpath":
+		if !hasArgs {
+			return nil, fmt.Errorf("invalid transform %q, expected jsonpath:<expression>", spec)
+		}
+
+		path, err := compileJSONPath(args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transform %q, %w", spec, err)
+		}
+
+		return func(value string) (string, error) {
+			node, err := path.selectJSON(value)
+			if err != nil {
+				return "", err
+			}
+
+			return jsonScalarString(node)
+		}, nil
+	}
+
+	if t, ok := simpleTransforms[strings.ToLower(strings.TrimSpace(spec))]; ok {
+		return t, nil
+	}
+
+	return nil, fmt.Errorf("unknown transform %q", spec)
+}
+
+// splitTransform returns a transform that splits the value by sep and selects the part at index.
+// Negative indexes count from the end.
+func splitTransform(sep string, index int) transform {
+	return func(value string) (string, error) {
+		parts := strings.Split(value, sep)
+
+		i := index
+		if i < 0 {
+			i += len(parts)
+		}
+
+		if i < 0 || i >= len(parts) {
+			return "", fmt.Errorf("split index %d out of range for %d parts", index, len(parts))
+		}
+
+		return parts[i], nil
+	}
+}
+
+// applyTransforms applies the transforms to the value in order.
+func applyTransforms(value string, transforms []transform) (string, error) {
+	var err error
+
+	for _, t := range transforms {
+		value, err = t(value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return value, nil
+}
+
+// urlDecode URL decodes the value according to the URL decode mode.
+func urlDecode(value string, mode string) (string, error) {
+	switch mode {
+	case URLDecodeRepeat:
+		for round := 0; round < maxURLDecodeRounds; round++ {
+			decoded, err := url.QueryUnescape(value)
+			if err != nil {
+				return "", err
+			}
+
+			if decoded == value {
+				return decoded, nil
+			}
+
+			value = decoded
+		}
+
+		return "", fmt.Errorf("value is still encoded after %d decoding rounds", maxURLDecodeRounds)
+	case URLDecodeRejectDouble:
+		decoded, err := url.QueryUnescape(value)
+		if err != nil {
+			return "", err
+		}
+
+		if percentEncodedRegex.MatchString(decoded) {
+			return "", fmt.Errorf("value is double percent-encoded")
+		}
+
+		return decoded, nil
+	default:
+		// Strict decoding; invalid escapes fail in every mode, as a value that can't be decoded can't be checked.
+		return url.QueryUnescape(value)
+	}
+}
+
+// validateURLDecodeMode checks whether the URL decode mode is supported.
+func validateURLDecodeMode(mode string) error {
+	switch mode {
+	case "", urlDecodeLenientAlias, URLDecodeStrict, URLDecodeRepeat, URLDecodeRejectDouble:
+		return nil
+	default:
+		return fmt.Errorf("unknown urldecodemode %q, allowed: %s, %s, %s", mode, URLDecodeStrict, URLDecodeRepeat, URLDecodeRejectDouble)
+	}
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
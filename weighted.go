@@ -0,0 +1,134 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PreferenceAny selects every element of a weighted list with a q-value above zero; the default.
+	PreferenceAny = "any"
+	// PreferenceHighest selects only the elements of a weighted list with the highest q-value.
+	PreferenceHighest = "highest"
+)
+
+// weightedElement is an element of a weighted list such as `de;q=0.9`.
+type weightedElement struct {
+	value   string
+	quality float64
+}
+
+// parseWeightedList parses a comma-separated list of elements with optional q-values, as used by
+// Accept-Language, Accept-Encoding and Accept-Charset. Elements with q=0 are excluded and the others
+// are ordered by descending q-value, keeping the header order for equal values.
+func parseWeightedList(value string) ([]weightedElement, error) {
+	var elements []weightedElement
+
+	for _, element := range strings.Split(value, ",") {
+		name, params, _ := strings.Cut(element, ";")
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			if strings.TrimSpace(params) != "" {
+				return nil, fmt.Errorf("missing value before parameters %q", params)
+			}
+
+			continue
+		}
+
+		quality := 1.0
+
+		for _, param := range strings.Split(params, ";") {
+			key, rawQuality, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+
+			q, err := strconv.ParseFloat(strings.TrimSpace(rawQuality), 64)
+			if err != nil || q < 0 || q > 1 {
+				return nil, fmt.Errorf("invalid quality %q for %q", rawQuality, name)
+			}
+
+			quality = q
+		}
+
+		if quality > 0 {
+			elements = append(elements, weightedElement{value: name, quality: quality})
+		}
+	}
+
+	sort.SliceStable(elements, func(i, j int) bool {
+		return elements[i].quality > elements[j].quality
+	})
+
+	return elements, nil
+}
+
+// weightedValues returns the values of the weighted list selected by the preference.
+func weightedValues(value string, preference string) ([]string, error) {
+	elements, err := parseWeightedList(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+
+	for _, element := range elements {
+		if preference == PreferenceHighest && element.quality < elements[0].quality {
+			break
+		}
+
+		values = append(values, element.value)
+	}
+
+	return values, nil
+}
+
+// validatePreference checks whether the preference is supported and used with a weighted list.
+func validatePreference(vHeader *SingleHeader) error {
+	switch vHeader.Preference {
+	case "":
+		return nil
+	case PreferenceAny, PreferenceHighest:
+		if vHeader.Source != SourceWeightedList {
+			return fmt.Errorf("preference can only be used in combination with a %s source", SourceWeightedList)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown preference %q, allowed: %s, %s", vHeader.Preference, PreferenceAny, PreferenceHighest)
+	}
+}
+
+// matchesLanguageRange checks whether a language tag matches a language range using the basic
+// filtering of RFC 4647: the range equals the tag or is a prefix of it followed by '-', ignoring
+// case. The range `*` matches every tag.
+func matchesLanguageRange(languageRange, tag string) bool {
+	if languageRange == "*" {
+		return true
+	}
+
+	if len(tag) < len(languageRange) || !strings.EqualFold(tag[:len(languageRange)], languageRange) {
+		return false
+	}
+
+	return len(tag) == len(languageRange) || tag[len(languageRange)] == '-'
+}
+
+// checkLanguage checks whether a language tag matches the configured language ranges.
+func checkLanguage(requestValue string, vHeader *SingleHeader) bool {
+	if vHeader.IsDebug() {
+		fmt.Println("validate-headers (debug): Validating language:", requestValue, vHeader.Values)
+	}
+
+	matchCount := 0
+	for _, value := range vHeader.Values {
+		if matchesLanguageRange(value, requestValue) {
+			matchCount++
+		}
+	}
+
+	return checkMatchCount(matchCount, vHeader)
+}
@@ -0,0 +1,219 @@
+package traefik_plugin_validate_headers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestWeightedValues(t *testing.T) {
+	tests := []struct {
+		preference string
+		value      string
+		expected   []string
+		expectErr  bool
+	}{
+		{value: "de-DE,de;q=0.9,en;q=0.1", expected: []string{"de-DE", "de", "en"}},
+		{value: "en;q=0.1, fr, de;q=0.9", expected: []string{"fr", "de", "en"}},
+		{value: "de, en;q=0, *;q=0.5", expected: []string{"de", "*"}},
+		{value: "gzip;q=1.0, br; Q=0.5", expected: []string{"gzip", "br"}},
+		{value: "en;q=0", expected: nil},
+		{value: "de,,en", expected: []string{"de", "en"}},
+		{preference: PreferenceHighest, value: "en;q=0.1, fr;q=0.8, de;q=0.8", expected: []string{"fr", "de"}},
+		{preference: PreferenceHighest, value: "de-DE,de;q=0.9", expected: []string{"de-DE"}},
+		{value: "de;q=high", expectErr: true},
+		{value: "de;q=1.5", expectErr: true},
+		{value: ";q=0.5", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preference+"_"+tt.value, func(t *testing.T) {
+			actual, err := weightedValues(tt.value, tt.preference)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", actual)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(actual) != fmt.Sprint(tt.expected) {
+				t.Errorf("got %q, want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchesLanguageRange(t *testing.T) {
+	tests := []struct {
+		languageRange string
+		tag           string
+		expected      bool
+	}{
+		{languageRange: "de", tag: "de", expected: true},
+		{languageRange: "de", tag: "DE-at", expected: true},
+		{languageRange: "de-DE", tag: "de-de-1996", expected: true},
+		{languageRange: "de", tag: "den", expected: false},
+		{languageRange: "de-DE", tag: "de", expected: false},
+		{languageRange: "*", tag: "fr", expected: true},
+	}
+
+	for _, tt := range tests {
+		if matchesLanguageRange(tt.languageRange, tt.tag) != tt.expected {
+			t.Errorf("range %q on tag %q: got %v, want %v", tt.languageRange, tt.tag, !tt.expected, tt.expected)
+		}
+	}
+}
+
+func TestWeightedList(t *testing.T) {
+	configTestPairs := []TestConfig{
+		//HighestPriorityAllowlistConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Accept-Language",
+						MatchType: string(MatchOne),
+						Values: []string{
+							"de",
+							"en-GB",
+						},
+						Language:   Bool(true),
+						Source:     SourceWeightedList,
+						Preference: PreferenceHighest,
+					},
+				},
+				Error: ErrorConfig{
+					ReasonHeader: "X-Validation-Reason",
+				},
+			},
+			tests: []Test{
+				{
+					name: "HighestPriorityAllowlist_Success",
+					headers: map[string]string{
+						"Accept-Language": "fr;q=0.5, de-AT, en-GB;q=0.9",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "HighestPriorityAllowlist_Fail_LowerPriorityOnly",
+					headers: map[string]string{
+						"Accept-Language": "fr, de;q=0.9",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Accept-Language: mismatch",
+				},
+				{
+					name: "HighestPriorityAllowlist_Fail_RegionNotAllowed",
+					headers: map[string]string{
+						"Accept-Language": "en-US, en-GB;q=0.9",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Accept-Language: mismatch",
+				},
+				{
+					name: "HighestPriorityAllowlist_Fail_Malformed",
+					headers: map[string]string{
+						"Accept-Language": "de;q=2",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Accept-Language: malformed",
+				},
+				{
+					name: "HighestPriorityAllowlist_Fail_NothingAcceptable",
+					headers: map[string]string{
+						"Accept-Language": "de;q=0",
+					},
+					expectedStatus: http.StatusForbidden,
+					expectedReason: "Accept-Language: missing",
+				},
+			},
+		},
+		//DenylistConfig
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:      "Accept-Language",
+						MatchType: string(MatchNone),
+						Values: []string{
+							"ru",
+							"zh-Hant",
+						},
+						Language: Bool(true),
+						Source:   SourceWeightedList,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name: "Denylist_Success",
+					headers: map[string]string{
+						"Accept-Language": "de-DE,de;q=0.9,zh-Hans;q=0.5,ru;q=0",
+					},
+					expectedStatus: http.StatusOK,
+				},
+				{
+					name: "Denylist_Fail_LowPriorityDenied",
+					headers: map[string]string{
+						"Accept-Language": "de-DE,de;q=0.9,ru-RU;q=0.1",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+				{
+					name: "Denylist_Fail_SubtagDenied",
+					headers: map[string]string{
+						"Accept-Language": "ZH-hant-TW",
+					},
+					expectedStatus: http.StatusForbidden,
+				},
+			},
+		},
+		// PreferenceWithoutWeightedList
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:       "Accept-Language",
+						MatchType:  string(MatchOne),
+						Values:     []string{"de"},
+						Source:     SourceList,
+						Preference: PreferenceHighest,
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "PreferenceWithoutWeightedList",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].source: preference can only be used in combination with a weighted-list source"),
+				},
+			},
+		},
+		// UnknownPreference
+		{
+			config: &Config{
+				Headers: []SingleHeader{
+					{
+						Name:       "Accept-Language",
+						MatchType:  string(MatchOne),
+						Values:     []string{"de"},
+						Source:     SourceWeightedList,
+						Preference: "first",
+					},
+				},
+			},
+			tests: []Test{
+				{
+					name:          "UnknownPreference",
+					expectedError: fmt.Errorf("validate-headers: configuration incorrect:\n  error: headers[0].source: unknown preference \"first\", allowed: any, highest"),
+				},
+			},
+		},
+	}
+
+	runValidatorTests(t, configTestPairs)
+}